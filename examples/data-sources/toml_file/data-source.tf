@@ -1,7 +1,39 @@
 data "toml_file" "example" {
-  input = file("${path.module}/example.toml")
+  filename = "${path.module}/example.toml"
 }
 
 output "toml_file_content" {
   value = data.toml_file.example.content
 }
+
+# TOML content can also be passed directly.
+data "toml_file" "inline" {
+  input = <<EOT
+name = "go-toml"
+EOT
+}
+
+# The content can be validated against a JSON Schema, so that any mistakes are
+# reported when planning.
+data "toml_file" "validated" {
+  filename = "${path.module}/pyproject.toml"
+  schema   = "${path.module}/schemas/pyproject.json"
+}
+
+# Arrays and tables can be decoded as lists and maps wherever their elements
+# have consistent types, so that they can be used with `for_each`.
+data "toml_file" "homogeneous" {
+  filename    = "${path.module}/example.toml"
+  collections = "homogeneous"
+}
+
+# The position of every key can be used to point at where a value is defined.
+check "name" {
+  assert {
+    condition = data.toml_file.example.content.name != ""
+    error_message = format(
+      "The name at line %d of example.toml must not be empty.",
+      data.toml_file.example.positions["name"].line,
+    )
+  }
+}
@@ -0,0 +1,7 @@
+# Writes `released = 2024-04-13T10:00:00Z` rather than a quoted string.
+resource "local_file" "release" {
+  filename = "${path.module}/release.toml"
+  content = provider::toml::encode({
+    released = provider::toml::datetime(timestamp())
+  })
+}
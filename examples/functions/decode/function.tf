@@ -1,3 +1,15 @@
 output "toml_file_content" {
   value = provider::toml::decode(file("${path.module}/example.toml"))
 }
+
+# Decodes dates and times as tagged objects, which `encode` writes back as
+# native TOML values rather than strings.
+output "toml_file_content_tagged" {
+  value = provider::toml::decode(file("${path.module}/example.toml"), { datetimes = "tagged" })
+}
+
+# Decodes arrays and tables as lists and maps wherever their elements have
+# consistent types, so that they can be used with `for_each`.
+output "toml_file_content_homogeneous" {
+  value = provider::toml::decode(file("${path.module}/example.toml"), { collections = "homogeneous" })
+}
@@ -0,0 +1,4 @@
+resource "local_file" "manifest" {
+  filename = "${path.module}/Cargo.toml"
+  content  = provider::toml::delete(file("${path.module}/Cargo.toml.in"), "dev-dependencies")
+}
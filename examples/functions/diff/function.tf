@@ -0,0 +1,17 @@
+# Shows reviewers which keys change between the deployed and proposed
+# configuration, matching [[bin]] entries by their name.
+locals {
+  deployed = file("${path.module}/deployed.toml")
+  proposed = file("${path.module}/config.toml")
+}
+
+output "changed_paths" {
+  value = [
+    for change in provider::toml::diff(local.deployed, local.proposed, { key_field = "name" }) :
+    "${change.op} ${change.path}"
+  ]
+}
+
+output "text_diff" {
+  value = provider::toml::diff(local.deployed, local.proposed, { output = "text" })
+}
@@ -17,3 +17,58 @@ resource "local_file" "my_toml_file" {
     }
   )
 }
+
+# Writes nested tables inline, and each array element on its own line.
+resource "local_file" "my_formatted_toml_file" {
+  filename = "formatted.toml"
+  content = provider::toml::encode(
+    {
+      name = "go-toml"
+      tags = ["go", "toml"]
+      section = {
+        subsection = {
+          items = ["something"]
+        }
+      }
+    },
+    {
+      table_indent     = "  "
+      inline_tables    = ["section.subsection"]
+      multiline_arrays = true
+      quote_style      = "basic"
+    }
+  )
+}
+
+# Bumps the version in an existing file, keeping its original table and key order.
+locals {
+  cargo_toml = file("${path.module}/Cargo.toml")
+  cargo      = provider::toml::decode(local.cargo_toml)
+}
+
+resource "local_file" "cargo_toml" {
+  filename = "${path.module}/Cargo.toml"
+  content = provider::toml::encode(
+    merge(local.cargo, {
+      package = merge(local.cargo.package, { version = "0.2.0" })
+    }),
+    { key_order = local.cargo_toml }
+  )
+}
+
+# Writes optional settings which are not set as comments, so that the generated
+# file documents every available setting.
+variable "log_level" {
+  type    = string
+  default = null
+}
+
+output "settings_toml" {
+  value = provider::toml::encode(
+    {
+      name      = "example"
+      log_level = var.log_level
+    },
+    { null_policy = "comment" }
+  )
+}
@@ -0,0 +1,20 @@
+locals {
+  settings = {
+    name     = "example"
+    released = "2024-04-13"
+    tags     = toset(["web", "api"])
+  }
+  settings_report = provider::toml::encode_report(local.settings)
+}
+
+# Fails the plan if any part of the settings would be changed or lost.
+check "settings_toml" {
+  assert {
+    condition     = length(local.settings_report.warnings) == 0
+    error_message = join("\n", [for w in local.settings_report.warnings : "${w.path}: ${w.message}"])
+  }
+}
+
+output "settings_toml" {
+  value = local.settings_report.toml
+}
@@ -0,0 +1,14 @@
+# Only rewrites the generated file when its meaning changes, ignoring
+# formatting, comments and whether numbers are written as floats.
+locals {
+  generated = provider::toml::encode({
+    replicas = 3
+    regions  = ["eu-west-1", "us-east-1"]
+  })
+}
+
+output "needs_update" {
+  value = !provider::toml::equal(file("${path.module}/config.toml"), local.generated, {
+    unordered_arrays = true
+  })
+}
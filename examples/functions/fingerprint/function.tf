@@ -0,0 +1,11 @@
+# Replaces the instance whenever the meaning of its configuration changes, but
+# not when only its comments or formatting do.
+resource "terraform_data" "config" {
+  input = provider::toml::fingerprint(file("${path.module}/config.toml"))
+}
+
+resource "terraform_data" "instance" {
+  lifecycle {
+    replace_triggered_by = [terraform_data.config]
+  }
+}
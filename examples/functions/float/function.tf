@@ -0,0 +1,9 @@
+# Telegraf expects `interval_seconds` to be a float, so `10` is written as `10.0`.
+resource "local_file" "telegraf_conf" {
+  filename = "${path.module}/telegraf.toml"
+  content = provider::toml::encode({
+    agent = {
+      interval_seconds = provider::toml::float(10)
+    }
+  })
+}
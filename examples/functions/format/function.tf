@@ -0,0 +1,14 @@
+# Checks that a hand-maintained file is formatted, keeping its comments.
+locals {
+  config_toml = file("${path.module}/config.toml")
+}
+
+check "config_formatted" {
+  assert {
+    condition = local.config_toml == provider::toml::format(local.config_toml, {
+      align_entries = true
+      reorder_keys  = true
+    })
+    error_message = "config.toml is not formatted."
+  }
+}
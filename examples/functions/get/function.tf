@@ -0,0 +1,11 @@
+locals {
+  manifest = file("${path.module}/Cargo.toml")
+}
+
+output "version" {
+  value = provider::toml::get(local.manifest, "package.version")
+}
+
+output "first_binary" {
+  value = provider::toml::get(local.manifest, "bin[0].name", "none")
+}
@@ -0,0 +1,3 @@
+output "uses_serde" {
+  value = provider::toml::has(file("${path.module}/Cargo.toml"), "dependencies.serde")
+}
@@ -0,0 +1,8 @@
+# Writes `mode = 0o755` and `color = 0xff8800`.
+resource "local_file" "config" {
+  filename = "${path.module}/config.toml"
+  content = provider::toml::encode({
+    mode  = provider::toml::integer(493, "octal")
+    color = provider::toml::integer(16746496, "hex")
+  })
+}
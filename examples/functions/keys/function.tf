@@ -0,0 +1,3 @@
+output "dependencies" {
+  value = provider::toml::keys(file("${path.module}/Cargo.toml"), "dependencies")
+}
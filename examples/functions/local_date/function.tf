@@ -0,0 +1,6 @@
+resource "local_file" "release" {
+  filename = "${path.module}/release.toml"
+  content = provider::toml::encode({
+    released = provider::toml::local_date(formatdate("YYYY-MM-DD", plantimestamp()))
+  })
+}
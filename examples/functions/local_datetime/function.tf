@@ -0,0 +1,6 @@
+resource "local_file" "schedule" {
+  filename = "${path.module}/schedule.toml"
+  content = provider::toml::encode({
+    maintenance_start = provider::toml::local_datetime("2024-04-14T02:00:00")
+  })
+}
@@ -0,0 +1,6 @@
+resource "local_file" "backup" {
+  filename = "${path.module}/backup.toml"
+  content = provider::toml::encode({
+    backup_at = provider::toml::local_time("02:30:00")
+  })
+}
@@ -0,0 +1,10 @@
+# Layers environment-specific overrides on top of a base configuration.
+resource "local_file" "config" {
+  filename = "${path.module}/config.toml"
+  content = provider::toml::encode(
+    provider::toml::merge(
+      file("${path.module}/base.toml"),
+      file("${path.module}/${terraform.workspace}.toml"),
+    )
+  )
+}
@@ -0,0 +1,12 @@
+# Layers environment-specific overrides on top of a base configuration. Entries
+# of arrays of tables with the same `name` are merged together.
+resource "local_file" "config" {
+  filename = "${path.module}/config.toml"
+  content = provider::toml::encode(
+    provider::toml::merge_with(
+      { arrays = "merge", key_field = "name", error_on_conflict = true },
+      file("${path.module}/base.toml"),
+      file("${path.module}/${terraform.workspace}.toml"),
+    )
+  )
+}
@@ -0,0 +1,10 @@
+resource "local_file" "config" {
+  filename = "${path.module}/config.toml"
+  content = provider::toml::encode({
+    motd = provider::toml::multiline(<<-EOT
+      Welcome!
+      Maintenance is scheduled for Sunday.
+    EOT
+    )
+  })
+}
@@ -0,0 +1,9 @@
+# Bumps the version in the manifest, keeping its comments and layout.
+resource "local_file" "manifest" {
+  filename = "${path.module}/Cargo.toml"
+  content = provider::toml::set(
+    file("${path.module}/Cargo.toml.in"),
+    "package.version",
+    "0.2.0",
+  )
+}
@@ -0,0 +1,12 @@
+variable "config" {
+  type = string
+
+  validation {
+    condition     = provider::toml::try_decode(var.config).valid
+    error_message = "The configuration is not valid TOML: ${join(", ", [for e in provider::toml::try_decode(var.config).errors : e.line != null ? "${e.message} (line ${e.line})" : e.message])}."
+  }
+}
+
+output "config" {
+  value = provider::toml::decode(var.config)
+}
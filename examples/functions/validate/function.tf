@@ -0,0 +1,15 @@
+locals {
+  violations = provider::toml::validate(
+    file("${path.module}/pyproject.toml"),
+    "${path.module}/schemas/pyproject.json",
+  )
+}
+
+check "pyproject" {
+  assert {
+    condition = length(local.violations) == 0
+    error_message = join("\n", [
+      for violation in local.violations : "${violation.path} (line ${violation.line}): ${violation.message}"
+    ])
+  }
+}
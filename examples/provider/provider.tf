@@ -6,3 +6,9 @@ terraform {
     }
   }
 }
+
+# The provider requires no configuration, but relative file paths can optionally
+# be resolved against a directory other than the current working directory.
+provider "toml" {
+  base_dir = path.module
+}
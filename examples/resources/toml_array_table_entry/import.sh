@@ -0,0 +1,4 @@
+# Entries of an array of tables can be imported using the path to the file, the
+# path of the array and the key identifying the entry, in the form
+# `<filename>#<path>#<key_field>=<value>`.
+terraform import toml_array_table_entry.cli './Cargo.toml#bin#name=example-cli'
@@ -0,0 +1,8 @@
+terraform {
+  required_providers {
+    toml = {
+      source  = "registry.terraform.io/tobotimus/toml"
+      version = ">=0.2.0"
+    }
+  }
+}
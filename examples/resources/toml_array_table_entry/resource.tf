@@ -0,0 +1,11 @@
+# Adds a `[[bin]]` entry to the manifest, leaving any other binaries (and the
+# rest of the file) as they are.
+resource "toml_array_table_entry" "cli" {
+  filename  = "${path.module}/Cargo.toml"
+  path      = "bin"
+  key_field = "name"
+  content = {
+    name = "example-cli"
+    path = "src/bin/cli.rs"
+  }
+}
@@ -0,0 +1,2 @@
+# TOML files can be imported using the path to the file.
+terraform import toml_file.example ./example.toml
@@ -0,0 +1,16 @@
+resource "toml_file" "example" {
+  filename = "${path.module}/example.toml"
+  content = {
+    version = 2
+    name    = "go-toml"
+    tags    = ["go", "toml"]
+
+    section = {
+      subsection = {
+        items = [
+          { include = "something" },
+        ]
+      }
+    }
+  }
+}
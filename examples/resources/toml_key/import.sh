@@ -0,0 +1,3 @@
+# TOML keys can be imported using the path to the file and the path of the key,
+# separated by `#`.
+terraform import toml_key.version './Cargo.toml#package.version'
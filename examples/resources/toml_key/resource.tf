@@ -0,0 +1,16 @@
+# Only the version is managed by Terraform, the rest of the file (including
+# comments) is left as it is.
+resource "toml_key" "version" {
+  filename = "${path.module}/Cargo.toml"
+  path     = "package.version"
+  value    = "1.2.3"
+}
+
+resource "toml_key" "dependency" {
+  filename = "${path.module}/Cargo.toml"
+  path     = "dependencies.serde"
+  value = {
+    version  = "1.0"
+    features = ["derive"]
+  }
+}
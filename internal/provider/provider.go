@@ -5,11 +5,15 @@ package provider
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure tomlProvider satisfies various provider interfaces.
@@ -28,16 +32,79 @@ func (p *TomlProvider) Metadata(ctx context.Context, req provider.MetadataReques
 	resp.Version = p.version
 }
 
-func (p *TomlProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+// TomlProviderModel describes the provider data model.
+type TomlProviderModel struct {
+	BaseDir types.String `tfsdk:"base_dir"`
+}
+
+// tomlProviderData is passed to data sources and resources once the provider
+// has been configured.
+type tomlProviderData struct {
+	// baseDir is the directory relative file paths are resolved against, or an
+	// empty string to resolve them against the current working directory.
+	baseDir string
+}
+
+// resolvePath resolves a file path against the configured base directory.
+func (d tomlProviderData) resolvePath(filename string) string {
+	if d.baseDir == "" || filepath.IsAbs(filename) {
+		return filename
+	}
+	return filepath.Join(d.baseDir, filename)
+}
+
+// absolutePath resolves a file path against the configured base directory,
+// and then against the current working directory if it is still relative.
+func (d tomlProviderData) absolutePath(filename string) (string, error) {
+	return filepath.Abs(d.resolvePath(filename))
+}
 
+func (p *TomlProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"base_dir": schema.StringAttribute{
+				Description: "Directory that relative file paths given to data sources and resources are " +
+					"resolved against. Defaults to the current working directory of Terraform, which is usually " +
+					"the root module directory.",
+				Optional: true,
+			},
+		},
+	}
 }
 
 func (p *TomlProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config TomlProviderModel
 
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.BaseDir.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("base_dir"),
+			"Unknown base directory",
+			"The provider cannot be configured because the value of `base_dir` is not known until apply. "+
+				"Use a value that is known during plan instead.",
+		)
+		return
+	}
+
+	data := tomlProviderData{
+		baseDir: config.BaseDir.ValueString(),
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
 }
 
 func (p *TomlProvider) Resources(ctx context.Context) []func() resource.Resource {
-	return nil
+	return []func() resource.Resource{
+		NewTomlFileResource,
+		NewTomlKeyResource,
+		NewTomlArrayTableEntryResource,
+	}
 }
 
 func (p *TomlProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
@@ -49,7 +116,28 @@ func (p *TomlProvider) DataSources(ctx context.Context) []func() datasource.Data
 func (p *TomlProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{
 		NewDecodeFunction,
+		NewTryDecodeFunction,
 		NewEncodeFunction,
+		NewEncodeReportFunction,
+		NewGetFunction,
+		NewHasFunction,
+		NewKeysFunction,
+		NewSetFunction,
+		NewDeleteFunction,
+		NewMergeFunction,
+		NewMergeWithFunction,
+		NewFormatFunction,
+		NewEqualFunction,
+		NewFingerprintFunction,
+		NewDiffFunction,
+		NewValidateFunction,
+		NewFloatFunction,
+		NewIntegerFunction,
+		NewMultilineFunction,
+		NewDatetimeFunction,
+		NewLocalDatetimeFunction,
+		NewLocalDateFunction,
+		NewLocalTimeFunction,
 	}
 }
 
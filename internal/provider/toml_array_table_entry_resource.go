@@ -0,0 +1,519 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &TomlArrayTableEntryResource{}
+	_ resource.ResourceWithConfigure   = &TomlArrayTableEntryResource{}
+	_ resource.ResourceWithImportState = &TomlArrayTableEntryResource{}
+	_ resource.ResourceWithModifyPlan  = &TomlArrayTableEntryResource{}
+)
+
+// NewTomlArrayTableEntryResource is a helper function to simplify the provider implementation.
+func NewTomlArrayTableEntryResource() resource.Resource {
+	return &TomlArrayTableEntryResource{}
+}
+
+// TomlArrayTableEntryResource is the resource implementation.
+type TomlArrayTableEntryResource struct {
+	providerData tomlProviderData
+}
+
+// Metadata returns the resource type name.
+func (r *TomlArrayTableEntryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_array_table_entry"
+}
+
+// Schema defines the schema for the resource.
+func (r *TomlArrayTableEntryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The `toml_array_table_entry` resource manages a single entry of an array of tables, such as " +
+			"a `[[bin]]` entry in a `Cargo.toml` file, within an existing TOML file. The entry is identified by the " +
+			"value of one of its keys. Other entries, and the comments and formatting of the rest of the file, are " +
+			"preserved.",
+		Attributes: map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path to the TOML file, which must already exist. Relative paths are resolved " +
+					"against the provider's `base_dir`. Changing this forces a new resource to be created.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"absolute_path": schema.StringAttribute{
+				Description: "The absolute path of the file, resolved from `filename` and the provider's `base_dir`. " +
+					"Changing this, for example by changing `base_dir`, forces a new resource to be created.",
+				Computed: true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path of the array of tables within the file, using TOML dotted key syntax, e.g. " +
+					"`bin` or `inputs.http`. The array is created if it doesn't exist. Changing this forces a new " +
+					"resource to be created.",
+				Required: true,
+				Validators: []validator.String{
+					tomlPathValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key_field": schema.StringAttribute{
+				Description: "The key whose value identifies the entry within the array, e.g. `name`. Changing " +
+					"this forces a new resource to be created.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"content": schema.DynamicAttribute{
+				Description: "The content of the entry, which must be an object containing the `key_field` key. " +
+					"The value is encoded in the same way as the `encode` function, with tables nested within the " +
+					"entry written inline.",
+				Required: true,
+			},
+			"id": schema.StringAttribute{
+				Description: "The filename, path of the array and identity of the entry, in the form " +
+					"`<filename>#<path>#<key_field>=<value>`.",
+				Computed: true,
+			},
+		},
+	}
+}
+
+// Configure stores the provider data for use when managing the entry.
+func (r *TomlArrayTableEntryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(tomlProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected resource configure type",
+			fmt.Sprintf("Expected tomlProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+// Create adds the entry to the array, or takes over an existing entry with the same key.
+func (r *TomlArrayTableEntryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan TomlArrayTableEntryResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyValue, ok := r.write(&plan, nil, "Create TOML array table entry resource error", &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id(keyValue))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the content of the entry in the file.
+func (r *TomlArrayTableEntryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state TomlArrayTableEntryResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	arrayPath, err := parseTomlPath(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Read TOML array table entry resource error", err.Error())
+		return
+	}
+
+	var keyValue any
+	if state.Content.IsNull() {
+		// The resource is being imported, so the entry is identified by the import identifier.
+		_, _, _, keyValueString, _ := parseArrayTableEntryID(state.ID.ValueString())
+		keyValue = keyValueString
+	} else {
+		keyValue, err = state.keyValue()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content"), "Read TOML array table entry resource error", err.Error())
+			return
+		}
+	}
+
+	filename := r.stateFilename(state)
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML array table entry resource error",
+			fmt.Sprintf("The file %q cannot be read.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	if state.AbsolutePath.IsNull() {
+		// Only happens when importing, or when the state was written by an
+		// earlier version of the provider.
+		absolutePath, err := filepath.Abs(filename)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Read TOML array table entry resource error",
+				fmt.Sprintf("The path of file %q cannot be resolved.\n\nOriginal Error: %s", filename, err),
+			)
+			return
+		}
+		state.AbsolutePath = types.StringValue(absolutePath)
+	}
+
+	var decodedContent any
+	if err := toml.Unmarshal(content, &decodedContent); err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML array table entry resource error",
+			fmt.Sprintf("The file %q cannot be decoded.\n\nOriginal Error: %s", filename, describeTomlDecodeError(content, filename, err)),
+		)
+		return
+	}
+
+	_, entry, ok := findArrayTableEntry(decodedContent, arrayPath, state.KeyField.ValueString(), keyValue, state.Content.IsNull())
+	if !ok {
+		// The entry has been removed outside of Terraform, so it must be added again.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	stateContent, err := convertFromTerraformType(state.Content)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Read TOML array table entry resource error",
+			fmt.Sprintf("The content in the state cannot be converted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	if state.Content.IsNull() || !tomlValuesEqual(entry, stateContent) {
+		// The entry has been changed outside of Terraform (or is being imported).
+		_, tfContent, diags := convertToTerraformType(entry)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Content = types.DynamicValue(tfContent)
+	}
+
+	keyValue, err = state.keyValue()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content"), "Read TOML array table entry resource error", err.Error())
+		return
+	}
+	state.ID = types.StringValue(state.id(keyValue))
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update rewrites the entry in place.
+func (r *TomlArrayTableEntryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state TomlArrayTableEntryResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The key of the entry may be changing, so look for the entry using its current key.
+	previousKeyValue, err := state.keyValue()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content"), "Update TOML array table entry resource error", err.Error())
+		return
+	}
+
+	keyValue, ok := r.write(&plan, previousKeyValue, "Update TOML array table entry resource error", &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	plan.ID = types.StringValue(plan.id(keyValue))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the entry from the array.
+func (r *TomlArrayTableEntryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state TomlArrayTableEntryResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	arrayPath, err := parseTomlPath(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Delete TOML array table entry resource error", err.Error())
+		return
+	}
+	keyValue, err := state.keyValue()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content"), "Delete TOML array table entry resource error", err.Error())
+		return
+	}
+
+	filename := r.stateFilename(state)
+	err = editTomlFile(filename, func(doc *tomlDocument) ([]byte, error) {
+		index, _, ok := findArrayTableEntry(doc.decoded, arrayPath, state.KeyField.ValueString(), keyValue, false)
+		if !ok {
+			return doc.data, nil
+		}
+		content, _, err := doc.Delete(arrayPath.join(indexElement(index)))
+		return content, err
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError(
+			"Delete TOML array table entry resource error",
+			fmt.Sprintf("The entry cannot be removed from %s in file %q.\n\nOriginal Error: %s", arrayPath, filename, err),
+		)
+	}
+}
+
+// ModifyPlan plans the absolute path of the file, replacing the entry when
+// it changes.
+func (r *TomlArrayTableEntryResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed.
+		return
+	}
+
+	var plan TomlArrayTableEntryResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.Filename.IsUnknown() {
+		return
+	}
+
+	absolutePath, err := r.providerData.absolutePath(plan.Filename.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filename"),
+			"Plan TOML array table entry resource error",
+			fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+	plan.AbsolutePath = types.StringValue(absolutePath)
+
+	// A relative filename refers to another file when the base directory
+	// changes, in which case the entry must be removed from the old file.
+	if !req.State.Raw.IsNull() {
+		var state TomlArrayTableEntryResourceModelV0
+
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !state.AbsolutePath.IsNull() && !state.AbsolutePath.Equal(plan.AbsolutePath) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("absolute_path"))
+		}
+	}
+
+	diags = resp.Plan.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState imports an existing entry, using an identifier of the form
+// `<filename>#<path>#<key_field>=<value>`.
+func (r *TomlArrayTableEntryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	filename, arrayPath, keyField, _, ok := parseArrayTableEntryID(req.ID)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			fmt.Sprintf("Expected an import identifier of the form <filename>#<path>#<key_field>=<value>, got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("filename"), filename)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("path"), arrayPath)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key_field"), keyField)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// write sets the planned content of the entry in the file, returning the value
+// of its key. The entry is found using previousKeyValue if set, or the key of
+// the planned content otherwise, and is appended to the array if not found.
+// The absolute path of the file is set in the plan.
+func (r *TomlArrayTableEntryResource) write(plan *TomlArrayTableEntryResourceModelV0, previousKeyValue any, summary string, diags *diag.Diagnostics) (any, bool) {
+	arrayPath, err := parseTomlPath(plan.Path.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("path"), summary, err.Error())
+		return nil, false
+	}
+	keyValue, err := plan.keyValue()
+	if err != nil {
+		diags.AddAttributeError(path.Root("content"), summary, err.Error())
+		return nil, false
+	}
+	if previousKeyValue == nil {
+		previousKeyValue = keyValue
+	}
+
+	content, err := convertFromTerraformType(plan.Content)
+	if err != nil {
+		diags.AddAttributeError(path.Root("content"), summary, err.Error())
+		return nil, false
+	}
+	filename, err := r.providerData.absolutePath(plan.Filename.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("filename"),
+			summary,
+			fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+		)
+		return nil, false
+	}
+	plan.AbsolutePath = types.StringValue(filename)
+
+	err = editTomlFile(filename, func(doc *tomlDocument) ([]byte, error) {
+		index, _, ok := findArrayTableEntry(doc.decoded, arrayPath, plan.KeyField.ValueString(), previousKeyValue, false)
+		if !ok {
+			entries, _ := lookupDecodedValue(doc.decoded, arrayPath)
+			existingEntries, _ := entries.([]any)
+			index = len(existingEntries)
+		}
+		return doc.Set(arrayPath.join(indexElement(index)), content)
+	})
+	if err != nil {
+		diags.AddError(
+			summary,
+			fmt.Sprintf("The entry cannot be written to %s in file %q.\n\nOriginal Error: %s", arrayPath, filename, err),
+		)
+		return nil, false
+	}
+	return keyValue, true
+}
+
+// findArrayTableEntry finds the entry of an array of tables with the given
+// value for the key field. If compareAsString is set, the value of the key
+// field is compared using its string representation.
+func findArrayTableEntry(decoded any, arrayPath tomlPath, keyField string, keyValue any, compareAsString bool) (int, map[string]any, bool) {
+	value, ok := lookupDecodedValue(decoded, arrayPath)
+	if !ok {
+		return 0, nil, false
+	}
+	entries, ok := value.([]any)
+	if !ok {
+		return 0, nil, false
+	}
+
+	for i, entry := range entries {
+		table, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		entryKeyValue, ok := table[keyField]
+		if !ok {
+			continue
+		}
+		entryKeyValue = normalizeDecodedValue(entryKeyValue)
+		if compareAsString && fmt.Sprint(entryKeyValue) == fmt.Sprint(keyValue) ||
+			!compareAsString && reflect.DeepEqual(entryKeyValue, keyValue) {
+			return i, table, true
+		}
+	}
+	return 0, nil, false
+}
+
+// parseArrayTableEntryID parses an identifier of the form
+// `<filename>#<path>#<key_field>=<value>`.
+func parseArrayTableEntryID(id string) (string, string, string, string, bool) {
+	filename, rest, ok := strings.Cut(id, "#")
+	if !ok {
+		return "", "", "", "", false
+	}
+	arrayPath, entry, ok := strings.Cut(rest, "#")
+	if !ok {
+		return "", "", "", "", false
+	}
+	keyField, keyValue, ok := strings.Cut(entry, "=")
+	if !ok || filename == "" || arrayPath == "" || keyField == "" {
+		return "", "", "", "", false
+	}
+	return filename, arrayPath, keyField, keyValue, true
+}
+
+type TomlArrayTableEntryResourceModelV0 struct {
+	Filename     types.String  `tfsdk:"filename"`
+	AbsolutePath types.String  `tfsdk:"absolute_path"`
+	Path         types.String  `tfsdk:"path"`
+	KeyField     types.String  `tfsdk:"key_field"`
+	Content      types.Dynamic `tfsdk:"content"`
+	ID           types.String  `tfsdk:"id"`
+}
+
+// keyValue returns the value of the key field of the entry content.
+func (m TomlArrayTableEntryResourceModelV0) keyValue() (any, error) {
+	content, err := convertFromTerraformType(m.Content)
+	if err != nil {
+		return nil, err
+	}
+	table, ok := content.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the content of the entry must be an object")
+	}
+	keyValue, ok := table[m.KeyField.ValueString()]
+	if !ok || keyValue == nil {
+		return nil, fmt.Errorf("the content of the entry must contain a value for the key field %q", m.KeyField.ValueString())
+	}
+	return keyValue, nil
+}
+
+func (m TomlArrayTableEntryResourceModelV0) id(keyValue any) string {
+	return fmt.Sprintf("%s#%s#%s=%v", m.Filename.ValueString(), m.Path.ValueString(), m.KeyField.ValueString(), keyValue)
+}
+
+// stateFilename returns the path of the file recorded in state, which is
+// where the entry was written even if the base directory has since changed.
+func (r *TomlArrayTableEntryResource) stateFilename(state TomlArrayTableEntryResourceModelV0) string {
+	if !state.AbsolutePath.IsNull() && !state.AbsolutePath.IsUnknown() {
+		return state.AbsolutePath.ValueString()
+	}
+	return r.providerData.resolvePath(state.Filename.ValueString())
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testAccTomlArrayTableEntryResourceConfig = `
+resource "toml_array_table_entry" "cli" {
+  filename  = %q
+  path      = "bin"
+  key_field = "name"
+  content = {
+    name = "cli"
+    path = %q
+  }
+}
+`
+
+	testAccTomlArrayTableEntryResourceBaseDirConfig = `
+provider "toml" {
+  base_dir = %q
+}
+
+resource "toml_array_table_entry" "cli" {
+  filename  = "Cargo.toml"
+  path      = "bin"
+  key_field = "name"
+  content = {
+    name = "cli"
+  }
+}
+`
+
+	testAccTomlArrayTableEntryResourceNullConfig = `
+resource "toml_array_table_entry" "cli" {
+  filename  = %q
+  path      = "bin"
+  key_field = "name"
+  content = {
+    name = "cli"
+    path = null
+  }
+}
+`
+
+	testAccTomlArrayTableEntryResourceTaggedConfig = `
+resource "toml_array_table_entry" "cli" {
+  filename  = %q
+  path      = "bin"
+  key_field = "name"
+  content = {
+    name    = "cli"
+    weight  = provider::toml::float(2)
+    mode    = provider::toml::integer(493, "octal")
+    release = provider::toml::local_date("2024-01-02")
+  }
+}
+`
+
+	testAccTomlArrayTableEntryResourceInitialContent = `[package]
+name = "example"
+
+# The main binary.
+[[bin]]
+name = "main" # Keep this comment.
+path = "src/main.rs"
+
+[dependencies]
+serde = "1.0"
+`
+
+	testAccTomlArrayTableEntryResourceExpectedContent = `[package]
+name = "example"
+
+# The main binary.
+[[bin]]
+name = "main" # Keep this comment.
+path = "src/main.rs"
+
+[[bin]]
+name = 'cli'
+path = 'src/bin/cli.rs'
+
+[dependencies]
+serde = "1.0"
+`
+)
+
+func TestAccTomlArrayTableEntryResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte(testAccTomlArrayTableEntryResourceInitialContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	id := filename + "#bin#name=cli"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			return testAccCheckFileContent(filename, testAccTomlArrayTableEntryResourceInitialContent)(nil)
+		},
+		Steps: []resource.TestStep{
+			// Create testing.
+			{
+				Config: fmt.Sprintf(testAccTomlArrayTableEntryResourceConfig, filename, "src/bin/cli.rs"),
+				Check:  testAccCheckFileContent(filename, testAccTomlArrayTableEntryResourceExpectedContent),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_array_table_entry.cli",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(id),
+					),
+				},
+			},
+			// Update testing.
+			{
+				Config: fmt.Sprintf(testAccTomlArrayTableEntryResourceConfig, filename, "src/cli.rs"),
+				Check: testAccCheckFileContent(filename, strings.Replace(
+					testAccTomlArrayTableEntryResourceExpectedContent, "'src/bin/cli.rs'", "'src/cli.rs'", 1,
+				)),
+			},
+			// Drift testing: the entry is removed outside of Terraform.
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filename, []byte(testAccTomlArrayTableEntryResourceInitialContent), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: fmt.Sprintf(testAccTomlArrayTableEntryResourceConfig, filename, "src/bin/cli.rs"),
+				Check:  testAccCheckFileContent(filename, testAccTomlArrayTableEntryResourceExpectedContent),
+			},
+			// Import testing.
+			{
+				ResourceName:      "toml_array_table_entry.cli",
+				ImportState:       true,
+				ImportStateId:     id,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccTomlArrayTableEntryResource_baseDir(t *testing.T) {
+	oldBaseDir := t.TempDir()
+	newBaseDir := t.TempDir()
+	for _, baseDir := range []string{oldBaseDir, newBaseDir} {
+		if err := os.WriteFile(filepath.Join(baseDir, "Cargo.toml"), []byte("[package]\nname = \"example\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			return testAccCheckFileContent(filepath.Join(newBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n")(nil)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTomlArrayTableEntryResourceBaseDirConfig, oldBaseDir),
+				Check:  testAccCheckFileContent(filepath.Join(oldBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n\n[[bin]]\nname = 'cli'\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_array_table_entry.cli",
+						tfjsonpath.New("absolute_path"),
+						knownvalue.StringExact(filepath.Join(oldBaseDir, "Cargo.toml")),
+					),
+				},
+			},
+			// Changing the base directory moves the entry to the other file.
+			{
+				Config: fmt.Sprintf(testAccTomlArrayTableEntryResourceBaseDirConfig, newBaseDir),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("toml_array_table_entry.cli", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFileContent(filepath.Join(oldBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n"),
+					testAccCheckFileContent(filepath.Join(newBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n\n[[bin]]\nname = 'cli'\n"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTomlArrayTableEntryResource_null(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte("[package]\nname = \"example\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(testAccTomlArrayTableEntryResourceNullConfig, filename)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, "[package]\nname = \"example\"\n\n[[bin]]\nname = 'cli'\n"),
+			},
+			// Null values are left out of the file, which is not drift.
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccTomlArrayTableEntryResource_tagged(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte("[package]\nname = \"example\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(testAccTomlArrayTableEntryResourceTaggedConfig, filename)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, "[package]\nname = \"example\"\n\n[[bin]]\nmode = 0o755\nname = 'cli'\nrelease = 2024-01-02\nweight = 2.0\n"),
+			},
+			// Tagged values are written as native TOML values, which is not drift.
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
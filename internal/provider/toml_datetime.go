@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = DatetimeFunction{}
+)
+
+// NewDatetimeFunction returns the datetime function, for offset date-times.
+func NewDatetimeFunction() function.Function {
+	return DatetimeFunction{
+		name:        "datetime",
+		tomlType:    tomlTypeOffsetDateTime,
+		description: "an offset date-time",
+		example:     "1979-05-27T07:32:00-08:00",
+	}
+}
+
+func NewLocalDatetimeFunction() function.Function {
+	return DatetimeFunction{
+		name:        "local_datetime",
+		tomlType:    tomlTypeLocalDateTime,
+		description: "a local date-time",
+		example:     "1979-05-27T07:32:00",
+	}
+}
+
+func NewLocalDateFunction() function.Function {
+	return DatetimeFunction{
+		name:        "local_date",
+		tomlType:    tomlTypeLocalDate,
+		description: "a local date",
+		example:     "1979-05-27",
+	}
+}
+
+func NewLocalTimeFunction() function.Function {
+	return DatetimeFunction{
+		name:        "local_time",
+		tomlType:    tomlTypeLocalTime,
+		description: "a local time",
+		example:     "07:32:00.999999",
+	}
+}
+
+// DatetimeFunction implements the functions which mark a string as a TOML date
+// or time value of a given type.
+type DatetimeFunction struct {
+	name        string
+	tomlType    string
+	description string
+	example     string
+}
+
+func (r DatetimeFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = r.name
+}
+
+func (r DatetimeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: fmt.Sprintf("Mark a string to be encoded as %s", r.description),
+		MarkdownDescription: strings.Join(
+			[]string{
+				fmt.Sprintf("Returns an object of the form `{ __toml_type = %q, value = \"...\" }`, which the `encode`", r.tomlType),
+				fmt.Sprintf("and `set` functions write as %s, such as `%s`, rather than as a string.", r.description, r.example),
+				"",
+				"The value is written exactly as given, after checking that it is valid. This is the same form",
+				"returned by the `decode` function with `datetimes = \"tagged\"`.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "value",
+				MarkdownDescription: fmt.Sprintf("Value of %s in TOML syntax", r.description),
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				tomlTypeAttribute:  types.StringType,
+				tomlValueAttribute: types.StringType,
+			},
+		},
+	}
+}
+
+func (r DatetimeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value types.String
+
+	resp.Error = req.Arguments.Get(ctx, &value)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if err := checkTaggedDatetime(r.tomlType, value.ValueString()); err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The value cannot be used as %s.\n\nOriginal Error: %s", r.description, err),
+		)
+		return
+	}
+
+	result, err := newTaggedValueObject(ctx, r.tomlType, value, "")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("The value cannot be created.\n\nOriginal Error: %s", err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testDatetimeConfig = `
+output "tagged" {
+	value = provider::toml::local_date("2024-04-13")
+}
+
+output "encoded" {
+	value = provider::toml::encode({
+		odt = provider::toml::datetime("1979-05-27T00:32:00.999999-07:00")
+		ldt = provider::toml::local_datetime("1979-05-27T07:32:00")
+		ld  = provider::toml::local_date("1979-05-27")
+		lt  = provider::toml::local_time("07:32:00")
+	})
+}
+`
+
+	testDatetimeExpectedOutput = `ld = 1979-05-27
+ldt = 1979-05-27T07:32:00
+lt = 07:32:00
+odt = 1979-05-27T00:32:00.999999-07:00
+`
+
+	testDatetimeInvalidConfig = `
+output "test" {
+	value = provider::toml::datetime("1979-05-27T07:32:00")
+}
+`
+
+	testDatetimeCommentConfig = `
+output "test" {
+	value = provider::toml::encode({ a = [provider::toml::local_date("1979-05-27 # x"), 1] })
+}
+`
+)
+
+func TestDatetimeFunctions(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testDatetimeInvalidConfig,
+				ExpectError: regexp.MustCompile(`is\s+not\s+a\s+valid\s+offset_datetime`),
+			},
+			{
+				Config:      testDatetimeCommentConfig,
+				ExpectError: regexp.MustCompile(`"1979-05-27\s+#\s+x"\s+is\s+not\s+a\s+valid\s+local_date`),
+			},
+			{
+				Config: testDatetimeConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"tagged",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"__toml_type": knownvalue.StringExact("local_date"),
+							"value":       knownvalue.StringExact("2024-04-13"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"encoded",
+						knownvalue.StringExact(testDatetimeExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
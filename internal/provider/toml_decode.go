@@ -8,6 +8,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/pelletier/go-toml/v2"
+	"math"
+	"math/big"
+	"sort"
 	"strings"
 	"time"
 )
@@ -40,7 +43,7 @@ func (r DecodeFunction) Definition(_ context.Context, _ function.DefinitionReque
 				"|--------------------|------------------------------------------------------------|",
 				"| `String`           | `string`                                                   |",
 				"| `Integer`          | `number`                                                   |",
-				"| `Float`            | `number`                                                   |",
+				"| `Float`            | `number`, or an object for `inf`, `-inf` and `nan`         |",
 				"| `Boolean`          | `bool`                                                     |",
 				"| `Offset Date-Time` | `string`, in RFC 3339 format                               |",
 				"| `Local Date-Time`  | `string`, in RFC 3339 format                               |",
@@ -50,6 +53,31 @@ func (r DecodeFunction) Definition(_ context.Context, _ function.DefinitionReque
 				"| `Inline Table`     | same as `Table`                                            |",
 				"| `Array`            | `tuple(...)` with element types determined per this table  |",
 				"| `Array of Tables`  | same as `Array` and `Table`                                |",
+				"",
+				"Since Terraform numbers cannot be infinite or NaN, the float values `inf`, `-inf` and `nan` are",
+				"decoded as objects of the form `{ __toml_type = \"float\", value = \"inf\" }`, which the `encode`",
+				"function writes back as the original values.",
+				"",
+				"An optional second argument is an object configuring the decoding. It supports the following",
+				"attributes:",
+				"",
+				"| Attribute     | Description                                                                           |",
+				"|---------------|---------------------------------------------------------------------------------------|",
+				"| `datetimes`   | How date and time values are decoded: `string` (default), or `tagged`.                |",
+				"| `collections` | How arrays and tables are decoded: `structural` (default), or `homogeneous`.          |",
+				"",
+				"With `datetimes = \"tagged\"`, date and time values are decoded as objects of the form",
+				"`{ __toml_type = \"local_date\", value = \"2024-04-13\" }`, where `__toml_type` is one of",
+				"`offset_datetime`, `local_datetime`, `local_date` or `local_time`. Offsets and fractional seconds",
+				"are preserved, and the `encode` function writes these objects back as native TOML values.",
+				"",
+				"With `collections = \"homogeneous\"`, arrays are decoded as lists and tables as maps wherever the",
+				"types of their elements unify, so that the result can be used with `for_each` or assigned to",
+				"variables of type `map(object(...))`. Integers and floats unify as numbers, and empty arrays and",
+				"tables unify with any list and map type. Arrays and tables whose elements cannot be unified, such",
+				"as arrays of tables with different keys, are decoded as tuples and objects as by default. The",
+				"`toml_file` data source reports the arrays, and the tables of tables, which are decoded this way",
+				"as warnings, which functions cannot.",
 			},
 			"\n",
 		),
@@ -57,33 +85,43 @@ func (r DecodeFunction) Definition(_ context.Context, _ function.DefinitionReque
 			function.StringParameter{
 				Name:                "input",
 				MarkdownDescription: "TOML file content to decode",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
 			},
 		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the decoding",
+		},
 		Return: function.DynamicReturn{},
 	}
 }
 
 func (r DecodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
 	var data string
+	var optionsArgs []types.Dynamic
 
-	resp.Error = req.Arguments.Get(ctx, &data)
+	resp.Error = req.Arguments.Get(ctx, &data, &optionsArgs)
 
 	if resp.Error != nil {
 		return
 	}
 
-	var decodedContent any
-	err := toml.Unmarshal([]byte(data), &decodedContent)
+	var options tomlDecoderOptions
+	options, resp.Error = parseDecodeOptionsArgument(optionsArgs)
+	if resp.Error != nil {
+		return
+	}
+
+	terraformValue, diags, err := decodeTomlContent([]byte(data), options)
 	if err != nil {
 		resp.Error = function.NewArgumentFuncError(
 			0,
-			fmt.Sprintf("The TOML file content cannot be decoded.\n\nOriginal Error: %s", err),
+			fmt.Sprintf("The TOML file content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(data), "", err)),
 		)
 		return
 	}
-
-	_, terraformValue, diags := convertToTerraformType(decodedContent)
-
 	if diags.HasError() {
 		resp.Error = function.FuncErrorFromDiags(ctx, diags)
 		return
@@ -92,9 +130,135 @@ func (r DecodeFunction) Run(ctx context.Context, req function.RunRequest, resp *
 	resp.Error = resp.Result.Set(ctx, types.DynamicValue(terraformValue))
 }
 
-func convertToTerraformType(dynamicValue any) (attr.Type, attr.Value, diag.Diagnostics) {
+// parseDecodeOptionsArgument parses the variadic options argument of the
+// functions which decode TOML content.
+func parseDecodeOptionsArgument(optionsArgs []types.Dynamic) (tomlDecoderOptions, *function.FuncError) {
+	options := tomlDecoderOptions{}
+	if len(optionsArgs) > 1 {
+		return options, function.NewArgumentFuncError(
+			2,
+			"At most one options argument may be given",
+		)
+	}
+	if len(optionsArgs) == 1 {
+		optionsValue, err := convertFromTerraformType(optionsArgs[0].UnderlyingValue())
+		if err == nil {
+			options, err = parseTomlDecoderOptions(optionsValue)
+		}
+		if err != nil {
+			return options, function.NewArgumentFuncError(
+				1,
+				fmt.Sprintf("The decode options are invalid.\n\nOriginal Error: %s", err),
+			)
+		}
+	}
+	return options, nil
+}
+
+// decodeTomlContent decodes TOML content to a Terraform value, as the decode
+// function does. Errors decoding the content are returned as is, so that they
+// can be located using locateTomlDecodeError, while errors converting the
+// decoded content are returned as diagnostics.
+func decodeTomlContent(data []byte, options tomlDecoderOptions) (attr.Value, diag.Diagnostics, error) {
+	var decodedContent any
+	if err := toml.Unmarshal(data, &decodedContent); err != nil {
+		return nil, nil, err
+	}
+
+	if options.taggedDatetimes {
+		decodedContent = tagDecodedValue(decodedContent)
+	}
+
+	_, terraformValue, diags := convertToTerraformType(decodedContent)
+	if diags.HasError() {
+		return nil, diags, nil
+	}
+
+	if options.homogeneousCollections {
+		// Functions cannot return warnings, so arrays and tables which cannot be
+		// converted are silently decoded as tuples and objects.
+		var fallbacks []tomlPath
+		terraformValue = homogenizeTerraformValue(nil, terraformValue, &fallbacks)
+	}
+	return terraformValue, diags, nil
+}
+
+// tomlDecoderOptions control how TOML documents are decoded by the decode function.
+type tomlDecoderOptions struct {
+	// taggedDatetimes decodes date and time values as tagged values, rather
+	// than strings.
+	taggedDatetimes bool
+	// homogeneousCollections decodes arrays and tables as lists and maps
+	// wherever possible, rather than tuples and objects.
+	homogeneousCollections bool
+}
+
+// parseTomlDecoderOptions parses the options argument of the decode function,
+// as returned by convertFromTerraformType.
+func parseTomlDecoderOptions(options any) (tomlDecoderOptions, error) {
+	var result tomlDecoderOptions
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return result, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "datetimes":
+			var datetimes string
+			datetimes, ok = value.(string)
+			if ok && datetimes != "string" && datetimes != "tagged" {
+				return result, fmt.Errorf("datetimes must be one of \"string\" or \"tagged\", got: %q", datetimes)
+			}
+			result.taggedDatetimes = datetimes == "tagged"
+		case "collections":
+			var collections string
+			collections, ok = value.(string)
+			if ok {
+				var err error
+				result.homogeneousCollections, err = parseTomlCollections(collections)
+				if err != nil {
+					return result, err
+				}
+			}
+		default:
+			return result, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return result, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+	return result, nil
+}
+
+// parseTomlCollections parses the collections option of the decode function
+// and the toml_file data source, returning whether arrays and tables are
+// decoded as lists and maps.
+func parseTomlCollections(collections string) (bool, error) {
+	if collections != "structural" && collections != "homogeneous" {
+		return false, fmt.Errorf("collections must be one of \"structural\" or \"homogeneous\", got: %q", collections)
+	}
+	return collections == "homogeneous", nil
+}
+
+// convertToTerraformType converts a decoded TOML value to a Terraform value.
+func convertToTerraformType(value any) (attr.Type, attr.Value, diag.Diagnostics) {
+	return convertValueToTerraformType(nil, value)
+}
+
+// convertValueToTerraformType converts the decoded TOML value at the given path.
+func convertValueToTerraformType(path tomlPath, dynamicValue any) (attr.Type, attr.Value, diag.Diagnostics) {
 	var diags diag.Diagnostics
 	switch value := dynamicValue.(type) {
+	case nil:
+		// TOML has no null value, but values built in Terraform may contain one.
+		return types.DynamicType, types.DynamicNull(), diags
 	case string:
 		return types.StringType, types.StringValue(value), diags
 	case int:
@@ -102,9 +266,15 @@ func convertToTerraformType(dynamicValue any) (attr.Type, attr.Value, diag.Diagn
 	case int64:
 		return types.Int64Type, types.Int64Value(value), diags
 	case float32:
-		return types.Float64Type, types.Float64Value(float64(value)), diags
+		return convertValueToTerraformType(path, float64(value))
 	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			// Terraform has no representation of these values.
+			return convertValueToTerraformType(path, newTaggedValue(tomlTypeFloat, formatSpecialFloat(value)))
+		}
 		return types.Float64Type, types.Float64Value(value), diags
+	case *big.Float:
+		return types.NumberType, types.NumberValue(value), diags
 	case bool:
 		return types.BoolType, types.BoolValue(value), diags
 	case time.Time:
@@ -119,7 +289,7 @@ func convertToTerraformType(dynamicValue any) (attr.Type, attr.Value, diag.Diagn
 		elementTypes := make([]attr.Type, len(value))
 		elementValues := make([]attr.Value, len(value))
 		for i, dynamicElementValue := range value {
-			elementType, elementValue, elementDiags := convertToTerraformType(dynamicElementValue)
+			elementType, elementValue, elementDiags := convertValueToTerraformType(path.join(indexElement(i)), dynamicElementValue)
 			elementTypes[i] = elementType
 			elementValues[i] = elementValue
 			diags.Append(elementDiags...)
@@ -134,7 +304,7 @@ func convertToTerraformType(dynamicValue any) (attr.Type, attr.Value, diag.Diagn
 		attributeTypes := make(map[string]attr.Type, len(value))
 		attributeValues := make(map[string]attr.Value, len(value))
 		for attributeName, dynamicAttributeValue := range value {
-			attributeType, attributeValue, attributeDiags := convertToTerraformType(dynamicAttributeValue)
+			attributeType, attributeValue, attributeDiags := convertValueToTerraformType(path.join(keyElement(attributeName)), dynamicAttributeValue)
 			attributeTypes[attributeName] = attributeType
 			attributeValues[attributeName] = attributeValue
 			diags.Append(attributeDiags...)
@@ -148,8 +318,253 @@ func convertToTerraformType(dynamicValue any) (attr.Type, attr.Value, diag.Diagn
 	default:
 		diags.AddError(
 			"Invalid type to convert to Terraform type",
-			fmt.Sprintf("Unable to convert value %v (type %T) to Terraform type", value, value),
+			fmt.Sprintf("Unable to convert value %v (type %T) at %s to Terraform type", value, value, formatDocumentPath(path)),
 		)
 		return nil, nil, diags
 	}
 }
+
+// homogenizeTerraformValue converts the tuples and objects of a value returned
+// by convertToTerraformType to lists and maps, wherever the types of their
+// elements unify. Numbers are converted to types.Number, so that integers and
+// floats unify, and tagged values are kept as objects.
+//
+// The paths of the tuples which cannot be converted are appended to
+// fallbacks, along with those of the objects whose values are all tables.
+// Other objects are records, such as `[package]`, which are expected to hold
+// values of different types.
+func homogenizeTerraformValue(path tomlPath, value attr.Value, fallbacks *[]tomlPath) attr.Value {
+	switch value := value.(type) {
+	case types.Int64:
+		return types.NumberValue(new(big.Float).SetInt64(value.ValueInt64()))
+	case types.Float64:
+		return types.NumberValue(big.NewFloat(value.ValueFloat64()))
+	case types.Tuple:
+		elements := make([]attr.Value, len(value.Elements()))
+		elementTypes := make([]attr.Type, len(elements))
+		for i, element := range value.Elements() {
+			elements[i] = homogenizeTerraformValue(path.join(indexElement(i)), element, fallbacks)
+			elementTypes[i] = elements[i].Type(context.Background())
+		}
+		if len(elements) == 0 {
+			return value
+		}
+		if elementType, ok := unifyTerraformTypes(elementTypes); ok {
+			for i, element := range elements {
+				elements[i] = convertToUnifiedType(element, elementType)
+			}
+			return types.ListValueMust(elementType, elements)
+		}
+		*fallbacks = append(*fallbacks, path)
+		return types.TupleValueMust(elementTypes, elements)
+	case types.Object:
+		if isTaggedValueObject(value) {
+			return value
+		}
+		attributes := make(map[string]attr.Value, len(value.Attributes()))
+		attributeTypes := make(map[string]attr.Type, len(attributes))
+		var elementTypes []attr.Type
+		for key, attribute := range value.Attributes() {
+			attributes[key] = homogenizeTerraformValue(path.join(keyElement(key)), attribute, fallbacks)
+			attributeTypes[key] = attributes[key].Type(context.Background())
+			elementTypes = append(elementTypes, attributeTypes[key])
+		}
+		if len(attributes) == 0 {
+			return value
+		}
+		if elementType, ok := unifyTerraformTypes(elementTypes); ok {
+			for key, attribute := range attributes {
+				attributes[key] = convertToUnifiedType(attribute, elementType)
+			}
+			return types.MapValueMust(elementType, attributes)
+		}
+		if isTableOfTables(attributes) {
+			*fallbacks = append(*fallbacks, path)
+		}
+		return types.ObjectValueMust(attributeTypes, attributes)
+	default:
+		return value
+	}
+}
+
+// unifyTerraformTypes returns the type to which values of all the given types
+// can be converted by convertToUnifiedType, if any.
+func unifyTerraformTypes(elementTypes []attr.Type) (attr.Type, bool) {
+	result := elementTypes[0]
+	for _, elementType := range elementTypes[1:] {
+		var ok bool
+		if result, ok = unifyTerraformType(result, elementType); !ok {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+// unifyTerraformType returns the type to which values of both types can be
+// converted, if any. Types must be equal, except that empty tuples and objects
+// can be converted to any list and map type respectively, including within
+// other types.
+func unifyTerraformType(a, b attr.Type) (attr.Type, bool) {
+	if a.Equal(b) {
+		return a, true
+	}
+	if isEmptyTupleOrObjectType(a) {
+		a, b = b, a
+	}
+
+	switch b := b.(type) {
+	case types.TupleType:
+		switch a := a.(type) {
+		case types.ListType:
+			return a, len(b.ElemTypes) == 0
+		case types.TupleType:
+			if len(a.ElemTypes) != len(b.ElemTypes) {
+				return nil, false
+			}
+			elementTypes := make([]attr.Type, len(a.ElemTypes))
+			for i := range a.ElemTypes {
+				var ok bool
+				if elementTypes[i], ok = unifyTerraformType(a.ElemTypes[i], b.ElemTypes[i]); !ok {
+					return nil, false
+				}
+			}
+			return types.TupleType{ElemTypes: elementTypes}, true
+		}
+	case types.ObjectType:
+		switch a := a.(type) {
+		case types.MapType:
+			return a, len(b.AttrTypes) == 0
+		case types.ObjectType:
+			if len(a.AttrTypes) != len(b.AttrTypes) {
+				return nil, false
+			}
+			attributeTypes := make(map[string]attr.Type, len(a.AttrTypes))
+			for key, attributeType := range a.AttrTypes {
+				otherType, ok := b.AttrTypes[key]
+				if !ok {
+					return nil, false
+				}
+				if attributeTypes[key], ok = unifyTerraformType(attributeType, otherType); !ok {
+					return nil, false
+				}
+			}
+			return types.ObjectType{AttrTypes: attributeTypes}, true
+		}
+	case types.ListType:
+		if a, ok := a.(types.ListType); ok {
+			elementType, ok := unifyTerraformType(a.ElemType, b.ElemType)
+			return types.ListType{ElemType: elementType}, ok
+		}
+	case types.MapType:
+		if a, ok := a.(types.MapType); ok {
+			elementType, ok := unifyTerraformType(a.ElemType, b.ElemType)
+			return types.MapType{ElemType: elementType}, ok
+		}
+	}
+	return nil, false
+}
+
+func isEmptyTupleOrObjectType(t attr.Type) bool {
+	switch t := t.(type) {
+	case types.TupleType:
+		return len(t.ElemTypes) == 0
+	case types.ObjectType:
+		return len(t.AttrTypes) == 0
+	}
+	return false
+}
+
+// convertToUnifiedType converts a value to a type returned by
+// unifyTerraformTypes, replacing empty tuples and objects with empty lists and
+// maps.
+func convertToUnifiedType(value attr.Value, t attr.Type) attr.Value {
+	switch t := t.(type) {
+	case types.ListType:
+		switch value := value.(type) {
+		case types.Tuple:
+			return types.ListValueMust(t.ElemType, nil)
+		case types.List:
+			elements := make([]attr.Value, len(value.Elements()))
+			for i, element := range value.Elements() {
+				elements[i] = convertToUnifiedType(element, t.ElemType)
+			}
+			return types.ListValueMust(t.ElemType, elements)
+		}
+	case types.MapType:
+		switch value := value.(type) {
+		case types.Object:
+			return types.MapValueMust(t.ElemType, nil)
+		case types.Map:
+			elements := make(map[string]attr.Value, len(value.Elements()))
+			for key, element := range value.Elements() {
+				elements[key] = convertToUnifiedType(element, t.ElemType)
+			}
+			return types.MapValueMust(t.ElemType, elements)
+		}
+	case types.TupleType:
+		if value, ok := value.(types.Tuple); ok {
+			elements := make([]attr.Value, len(value.Elements()))
+			for i, element := range value.Elements() {
+				elements[i] = convertToUnifiedType(element, t.ElemTypes[i])
+			}
+			return types.TupleValueMust(t.ElemTypes, elements)
+		}
+	case types.ObjectType:
+		if value, ok := value.(types.Object); ok {
+			attributes := make(map[string]attr.Value, len(value.Attributes()))
+			for key, attribute := range value.Attributes() {
+				attributes[key] = convertToUnifiedType(attribute, t.AttrTypes[key])
+			}
+			return types.ObjectValueMust(t.AttrTypes, attributes)
+		}
+	}
+	return value
+}
+
+// isTableOfTables returns whether every value of a table is itself a table.
+func isTableOfTables(attributes map[string]attr.Value) bool {
+	for _, attribute := range attributes {
+		switch attribute := attribute.(type) {
+		case types.Map:
+		case types.Object:
+			if isTaggedValueObject(attribute) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isTaggedValueObject returns whether an object is a tagged value.
+func isTaggedValueObject(object types.Object) bool {
+	attributes := object.Attributes()
+	if _, ok := attributes[tomlTypeAttribute].(types.String); !ok {
+		return false
+	}
+	if _, ok := attributes[tomlValueAttribute]; !ok {
+		return false
+	}
+	for key := range attributes {
+		if key != tomlTypeAttribute && key != tomlValueAttribute && key != tomlFormatAttribute {
+			return false
+		}
+	}
+	return true
+}
+
+// formatHomogenizeFallbacks describes the tuples and objects which could not
+// be converted by homogenizeTerraformValue.
+func formatHomogenizeFallbacks(fallbacks []tomlPath) string {
+	paths := make([]string, len(fallbacks))
+	for i, path := range fallbacks {
+		paths[i] = formatDocumentPath(path)
+	}
+	sort.Strings(paths)
+	return fmt.Sprintf(
+		"The elements of the following arrays and tables have types which cannot be unified, so they are "+
+			"decoded as tuples and objects rather than lists and maps: %s",
+		strings.Join(paths, ", "),
+	)
+}
@@ -1,13 +1,20 @@
 package provider
 
 import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
 	"testing"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
 	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/pelletier/go-toml/v2"
 )
 
 const testDecodeConfig = `
@@ -33,6 +40,81 @@ EOF
 }
 `
 
+const testDecodeTaggedDatetimesConfig = `
+locals {
+	document = <<EOF
+odt_value = 1979-05-27T00:32:00.999999-07:00
+ldt_value = 1979-05-27T07:32:00.500
+ld_value = 1979-05-27
+lt_value = [07:32:00, 00:32:00.999999]
+EOF
+	decoded = provider::toml::decode(local.document, { datetimes = "tagged" })
+}
+
+output "decoded" {
+	value = local.decoded
+}
+
+output "encoded" {
+	value = provider::toml::encode(local.decoded)
+}
+`
+
+const testDecodeTaggedDatetimesExpectedOutput = `ld_value = 1979-05-27
+ldt_value = 1979-05-27T07:32:00.500
+lt_value = [07:32:00, 00:32:00.999999]
+odt_value = 1979-05-27T00:32:00.999999-07:00
+`
+
+const testDecodeInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::decode("a = 1", { datetimes = "native" })
+}
+`
+
+const testEncodeInvalidTaggedValueConfig = `
+output "test" {
+	value = provider::toml::encode({ a = { __toml_type = "local_date", value = "1979-05-27T07:32:00" } })
+}
+`
+
+const testDecodeCollectionsConfig = `
+locals {
+	decoded = provider::toml::decode(<<EOF
+ports = [80, 443]
+
+[servers.alpha]
+ip = "10.0.0.1"
+roles = ["web"]
+
+[servers.beta]
+ip = "10.0.0.2"
+roles = []
+EOF
+	, { collections = "homogeneous" })
+}
+
+output "decoded" {
+	value = local.decoded
+}
+
+output "roles" {
+	value = { for name, server in local.decoded.servers : name => length(server.roles) }
+}
+`
+
+const testDecodeInvalidConfig = `
+output "test" {
+	value = provider::toml::decode("a = 1\nb = \"\\q\"\n")
+}
+`
+
+const testDecodeInvalidCollectionsConfig = `
+output "test" {
+	value = provider::toml::decode("a = 1", { collections = "lists" })
+}
+`
+
 func TestDecodeFunction(t *testing.T) {
 	resource.UnitTest(t, resource.TestCase{
 		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
@@ -40,6 +122,10 @@ func TestDecodeFunction(t *testing.T) {
 		},
 		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
 		Steps: []resource.TestStep{
+			{
+				Config:      testDecodeInvalidConfig,
+				ExpectError: regexp.MustCompile(`At\s+line\s+2,\s+column\s+7\s+\(key\s+b\):`),
+			},
 			{
 				Config: testDecodeConfig,
 				ConfigStateChecks: []statecheck.StateCheck{
@@ -70,3 +156,210 @@ func TestDecodeFunction(t *testing.T) {
 		},
 	})
 }
+
+func TestDecodeFunction_taggedDatetimes(t *testing.T) {
+	tagged := func(tomlType string, value string) knownvalue.Check {
+		return knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"__toml_type": knownvalue.StringExact(tomlType),
+			"value":       knownvalue.StringExact(value),
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testDecodeInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`datetimes\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config:      testEncodeInvalidTaggedValueConfig,
+				ExpectError: regexp.MustCompile(`is\s+not\s+a\s+valid\s+local_date`),
+			},
+			{
+				Config: testDecodeTaggedDatetimesConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"decoded",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"odt_value": tagged("offset_datetime", "1979-05-27T00:32:00.999999-07:00"),
+							"ldt_value": tagged("local_datetime", "1979-05-27T07:32:00.500"),
+							"ld_value":  tagged("local_date", "1979-05-27"),
+							"lt_value": knownvalue.ListExact([]knownvalue.Check{
+								tagged("local_time", "07:32:00"),
+								tagged("local_time", "00:32:00.999999"),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"encoded",
+						knownvalue.StringExact(testDecodeTaggedDatetimesExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestHomogenizeTerraformValue(t *testing.T) {
+	server := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"ip":    types.StringType,
+		"roles": types.ListType{ElemType: types.StringType},
+	}}
+
+	testCases := map[string]struct {
+		document  string
+		expected  attr.Type
+		fallbacks []string
+	}{
+		"numbers": {
+			document: "a = [1, 2.5]",
+			expected: types.MapType{ElemType: types.ListType{ElemType: types.NumberType}},
+		},
+		"empty arrays": {
+			document: "a = []\nb = [[], [1]]\nc = [{x = []}, {x = ['y']}]",
+			expected: types.ObjectType{AttrTypes: map[string]attr.Type{
+				"a": types.TupleType{},
+				"b": types.ListType{ElemType: types.ListType{ElemType: types.NumberType}},
+				"c": types.ListType{ElemType: types.MapType{ElemType: types.ListType{ElemType: types.StringType}}},
+			}},
+		},
+		"tables of records": {
+			document: "[servers.alpha]\nip = '10.0.0.1'\nroles = ['web']\n\n[servers.beta]\nip = '10.0.0.2'\nroles = []\n",
+			expected: types.MapType{ElemType: types.MapType{ElemType: server}},
+		},
+		"array of tables": {
+			document: "[[bin]]\nname = 'a'\n\n[[bin]]\nname = 'b'\npath = 'b.rs'\n",
+			expected: types.MapType{ElemType: types.ListType{ElemType: types.MapType{ElemType: types.StringType}}},
+		},
+		"inconsistent array": {
+			document: "a = [1, 'two']\n[[bin]]\nname = 'a'\ntest = true\n\n[[bin]]\nname = 'b'\n",
+			expected: types.ObjectType{AttrTypes: map[string]attr.Type{
+				"a": types.TupleType{ElemTypes: []attr.Type{types.NumberType, types.StringType}},
+				"bin": types.TupleType{ElemTypes: []attr.Type{
+					types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "test": types.BoolType}},
+					types.MapType{ElemType: types.StringType},
+				}},
+			}},
+			fallbacks: []string{"a", "bin"},
+		},
+		"inconsistent tables": {
+			document: "[servers.alpha]\nip = '10.0.0.1'\n\n[servers.beta]\nip = '10.0.0.2'\nport = 22\n",
+			expected: types.MapType{ElemType: types.ObjectType{AttrTypes: map[string]attr.Type{
+				"alpha": types.MapType{ElemType: types.StringType},
+				"beta": types.ObjectType{AttrTypes: map[string]attr.Type{
+					"ip":   types.StringType,
+					"port": types.NumberType,
+				}},
+			}}},
+			fallbacks: []string{"servers"},
+		},
+		"tagged values": {
+			document: "a = [inf, nan]",
+			expected: types.MapType{ElemType: types.ListType{ElemType: types.ObjectType{AttrTypes: map[string]attr.Type{
+				"__toml_type": types.StringType,
+				"value":       types.StringType,
+			}}}},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var decoded any
+			if err := toml.Unmarshal([]byte(testCase.document), &decoded); err != nil {
+				t.Fatal(err)
+			}
+			_, value, diags := convertToTerraformType(decoded)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			var fallbacks []tomlPath
+			actual := homogenizeTerraformValue(nil, value, &fallbacks)
+			if actualType := actual.Type(context.Background()); !actualType.Equal(testCase.expected) {
+				t.Errorf("unexpected type %s, expected %s", actualType, testCase.expected)
+			}
+			var actualFallbacks []string
+			for _, path := range fallbacks {
+				actualFallbacks = append(actualFallbacks, path.String())
+			}
+			sort.Strings(actualFallbacks)
+			if !reflect.DeepEqual(actualFallbacks, testCase.fallbacks) {
+				t.Errorf("unexpected fallbacks %v, expected %v", actualFallbacks, testCase.fallbacks)
+			}
+		})
+	}
+}
+
+func TestDecodeFunction_collections(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testDecodeInvalidCollectionsConfig,
+				ExpectError: regexp.MustCompile(`collections\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config: testDecodeCollectionsConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"decoded",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"ports": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.Int64Exact(80),
+								knownvalue.Int64Exact(443),
+							}),
+							"servers": knownvalue.MapExact(map[string]knownvalue.Check{
+								"alpha": knownvalue.ObjectExact(map[string]knownvalue.Check{
+									"ip":    knownvalue.StringExact("10.0.0.1"),
+									"roles": knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact("web")}),
+								}),
+								"beta": knownvalue.ObjectExact(map[string]knownvalue.Check{
+									"ip":    knownvalue.StringExact("10.0.0.2"),
+									"roles": knownvalue.ListExact([]knownvalue.Check{}),
+								}),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"roles",
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"alpha": knownvalue.Int64Exact(1),
+							"beta":  knownvalue.Int64Exact(0),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestConvertToTerraformType_unsupportedType(t *testing.T) {
+	_, _, diags := convertToTerraformType(map[string]any{"a": []any{"b", complex(1, 2)}})
+	if !diags.HasError() {
+		t.Fatal("expected error")
+	}
+	if expected := "Unable to convert value (1+2i) (type complex128) at a[1] to Terraform type"; diags[0].Detail() != expected {
+		t.Errorf("unexpected error %q, expected %q", diags[0].Detail(), expected)
+	}
+}
+
+func TestConvertToTerraformType_null(t *testing.T) {
+	valueType, value, diags := convertToTerraformType(map[string]any{"a": nil})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	expected := types.ObjectValueMust(
+		map[string]attr.Type{"a": types.DynamicType},
+		map[string]attr.Value{"a": types.DynamicNull()},
+	)
+	if !value.Equal(expected) || !valueType.Equal(expected.Type(context.Background())) {
+		t.Errorf("unexpected value %s, expected %s", value, expected)
+	}
+}
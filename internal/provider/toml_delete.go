@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = DeleteFunction{}
+)
+
+func NewDeleteFunction() function.Function {
+	return DeleteFunction{}
+}
+
+type DeleteFunction struct{}
+
+func (r DeleteFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "delete"
+}
+
+func (r DeleteFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Delete the value at a path within TOML content",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Deletes the value at the given path within TOML content, returning the modified content.",
+				"",
+				"Deleting a table also deletes its sub-tables, and comments directly above a deleted key or table",
+				"are deleted with it. The rest of the content is preserved as it is. If the path does not exist,",
+				"the content is returned unchanged.",
+				"",
+				"The path uses the same syntax as the `get` function.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "input",
+				MarkdownDescription: "TOML content to modify",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path of the value within the content",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (r DeleteFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var data, pathString string
+
+	resp.Error = req.Arguments.Get(ctx, &data, &pathString)
+
+	if resp.Error != nil {
+		return
+	}
+
+	doc, keyPath, funcErr := parseDocumentAndPath(data, pathString)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	content, _, err := doc.Delete(keyPath)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The value at path %q cannot be deleted.\n\nOriginal Error: %s", pathString, err),
+		)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.StringValue(string(content)))
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testDeleteConfig = testEditFunctionDocument + `
+output "key" {
+	value = provider::toml::delete(local.document, "package.version")
+}
+
+output "table" {
+	value = provider::toml::delete(local.document, "bin")
+}
+
+output "missing" {
+	value = provider::toml::delete(local.document, "package.edition") == local.document
+}
+`
+
+	testDeleteKeyExpectedOutput = `# Package metadata.
+[package]
+name = "example" # The crate name.
+
+[[bin]]
+name = "first"
+`
+
+	testDeleteTableExpectedOutput = `# Package metadata.
+[package]
+name = "example" # The crate name.
+version = "0.1.0"
+`
+)
+
+func TestDeleteFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDeleteConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("key", knownvalue.StringExact(testDeleteKeyExpectedOutput)),
+					statecheck.ExpectKnownOutputValue("table", knownvalue.StringExact(testDeleteTableExpectedOutput)),
+					statecheck.ExpectKnownOutputValue("missing", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,441 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ function.Function = DiffFunction{}
+)
+
+func NewDiffFunction() function.Function {
+	return DiffFunction{}
+}
+
+type DiffFunction struct{}
+
+func (r DiffFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "diff"
+}
+
+func (r DiffFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "List the changes between two TOML documents",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Compares two TOML documents and returns the keys which are added, removed or changed. Each",
+				"document may be TOML content as a string, or a value which has already been decoded.",
+				"",
+				"The result is a list of objects with the following attributes, in the order of the sorted keys:",
+				"",
+				"| Attribute | Description                                                                        |",
+				"|-----------|------------------------------------------------------------------------------------|",
+				"| `op`      | `add`, `remove` or `change`.                                                       |",
+				"| `path`    | The path of the value, in the same syntax as the `get` function.                   |",
+				"| `old`     | The value in the old document, converted as by `decode`, or null if it is added.   |",
+				"| `new`     | The value in the new document, converted as by `decode`, or null if it is removed. |",
+				"",
+				"Tables are compared recursively, as are arrays of tables, whose entries are matched by index unless",
+				"`key_field` is set. Other values, including arrays which do not contain tables, are compared as a",
+				"whole in the same way as the `equal` function, so an integer is not changed to a float of the same",
+				"value.",
+				"",
+				"An optional third argument is an object configuring the comparison. It supports the following",
+				"attributes:",
+				"",
+				"| Attribute   | Description                                                                            |",
+				"|-------------|----------------------------------------------------------------------------------------|",
+				"| `key_field` | The key identifying entries of arrays of tables, which are otherwise matched by index. |",
+				"| `output`    | `changes` (default) to return the list of changes, or `text` to return a text diff.    |",
+				"",
+				"With `key_field`, entries which have the same value for the key are compared, and the paths of",
+				"their changes use the index of the entry in the new document. Arrays with entries which do not have",
+				"the key are matched by index.",
+				"",
+				"With `output = \"text\"`, the result is a string in the style of a unified diff, with a line",
+				"starting with `-` for each removed or old value and a line starting with `+` for each added or",
+				"new value, or an empty string if the documents are equal.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "old",
+				MarkdownDescription: "Old TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+			function.DynamicParameter{
+				Name:                "new",
+				MarkdownDescription: "New TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the comparison",
+			AllowUnknownValues:  true,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (r DiffFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var oldDocument, newDocument types.Dynamic
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &oldDocument, &newDocument, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	known := isFullyKnown(ctx, oldDocument) && isFullyKnown(ctx, newDocument)
+	for _, optionsArg := range optionsArgs {
+		known = known && isFullyKnown(ctx, optionsArg)
+	}
+	if !known {
+		resp.Error = resp.Result.Set(ctx, types.DynamicUnknown())
+		return
+	}
+
+	differ := tomlDiffer{output: "changes"}
+	if len(optionsArgs) > 1 {
+		resp.Error = function.NewArgumentFuncError(
+			3,
+			"At most one options argument may be given",
+		)
+		return
+	}
+	if len(optionsArgs) == 1 {
+		optionsValue, err := convertFromTerraformType(optionsArgs[0].UnderlyingValue())
+		if err == nil {
+			differ, err = newTomlDiffer(optionsValue)
+		}
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(
+				2,
+				fmt.Sprintf("The diff options are invalid.\n\nOriginal Error: %s", err),
+			)
+			return
+		}
+	}
+
+	var tables [2]map[string]any
+	for i, document := range []types.Dynamic{oldDocument, newDocument} {
+		decodedContent, funcErr := decodeDocumentArgument(document, int64(i))
+		if funcErr != nil {
+			resp.Error = funcErr
+			return
+		}
+
+		table, ok := withoutNulls(decodedContent).(map[string]any)
+		if !ok {
+			resp.Error = function.NewArgumentFuncError(
+				int64(i),
+				fmt.Sprintf("Document %d is not a table", i+1),
+			)
+			return
+		}
+		tables[i] = table
+	}
+
+	var changes []tomlChange
+	differ.diff(nil, tables[0], tables[1], &changes)
+
+	if differ.output == "text" {
+		text, err := formatTomlChanges(changes)
+		if err != nil {
+			resp.Error = function.NewFuncError(fmt.Sprintf("The changes cannot be formatted.\n\nOriginal Error: %s", err))
+			return
+		}
+		resp.Error = resp.Result.Set(ctx, types.DynamicValue(types.StringValue(text)))
+		return
+	}
+
+	result, diags := convertTomlChangesToTerraformType(ctx, changes)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(result))
+}
+
+// tomlChange is a value which is added, removed or changed between two
+// decoded TOML documents. The old value is nil if it is added, and the new
+// value is nil if it is removed.
+type tomlChange struct {
+	op       string
+	path     tomlPath
+	oldValue any
+	newValue any
+}
+
+// tomlDiffer compares decoded TOML documents.
+type tomlDiffer struct {
+	keyField string
+	output   string
+}
+
+// newTomlDiffer creates a tomlDiffer from the options argument of the diff function.
+func newTomlDiffer(options any) (tomlDiffer, error) {
+	differ := tomlDiffer{output: "changes"}
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return differ, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "key_field":
+			differ.keyField, ok = value.(string)
+		case "output":
+			differ.output, ok = value.(string)
+			if ok && differ.output != "changes" && differ.output != "text" {
+				return differ, fmt.Errorf("output must be one of \"changes\" or \"text\", got: %q", differ.output)
+			}
+		default:
+			return differ, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return differ, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+	return differ, nil
+}
+
+// diff appends the changes from oldValue to newValue at the given path.
+func (d tomlDiffer) diff(path tomlPath, oldValue, newValue any, changes *[]tomlChange) {
+	oldTable, oldIsTable := asDiffTable(oldValue)
+	newTable, newIsTable := asDiffTable(newValue)
+	if oldIsTable && newIsTable {
+		d.diffTables(path, oldTable, newTable, changes)
+		return
+	}
+
+	oldArray, oldIsArray := oldValue.([]any)
+	newArray, newIsArray := newValue.([]any)
+	if oldIsArray && newIsArray && isArrayOfTables(oldArray) && isArrayOfTables(newArray) {
+		d.diffArraysOfTables(path, oldArray, newArray, changes)
+		return
+	}
+
+	options := tomlComparisonOptions{}
+	if canonicalTomlValue(oldValue, options) != canonicalTomlValue(newValue, options) {
+		*changes = append(*changes, tomlChange{op: "change", path: path, oldValue: oldValue, newValue: newValue})
+	}
+}
+
+func (d tomlDiffer) diffTables(path tomlPath, oldTable, newTable map[string]any, changes *[]tomlChange) {
+	keys := make([]string, 0, len(oldTable)+len(newTable))
+	for key := range oldTable {
+		keys = append(keys, key)
+	}
+	for key := range newTable {
+		if _, ok := oldTable[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := path.join(keyElement(key))
+		oldValue, oldOk := oldTable[key]
+		newValue, newOk := newTable[key]
+		switch {
+		case !newOk:
+			*changes = append(*changes, tomlChange{op: "remove", path: keyPath, oldValue: oldValue})
+		case !oldOk:
+			*changes = append(*changes, tomlChange{op: "add", path: keyPath, newValue: newValue})
+		default:
+			d.diff(keyPath, oldValue, newValue, changes)
+		}
+	}
+}
+
+// diffArraysOfTables compares the entries of two arrays of tables, matching
+// them by the value of the key field if every entry has one, and otherwise by
+// index. Removed entries are listed before the other changes.
+func (d tomlDiffer) diffArraysOfTables(path tomlPath, oldArray, newArray []any, changes *[]tomlChange) {
+	if d.keyField == "" || !hasKeyField(oldArray, d.keyField) || !hasKeyField(newArray, d.keyField) {
+		for i := 0; i < max(len(oldArray), len(newArray)); i++ {
+			indexPath := path.join(indexElement(i))
+			switch {
+			case i >= len(newArray):
+				*changes = append(*changes, tomlChange{op: "remove", path: indexPath, oldValue: oldArray[i]})
+			case i >= len(oldArray):
+				*changes = append(*changes, tomlChange{op: "add", path: indexPath, newValue: newArray[i]})
+			default:
+				d.diff(indexPath, oldArray[i], newArray[i], changes)
+			}
+		}
+		return
+	}
+
+	keyOf := func(entry any) string {
+		return canonicalTomlValue(entry.(map[string]any)[d.keyField], tomlComparisonOptions{})
+	}
+
+	// Match each new entry with the first unmatched old entry with the same key.
+	oldIndexes := make(map[string][]int, len(oldArray))
+	for i, entry := range oldArray {
+		oldIndexes[keyOf(entry)] = append(oldIndexes[keyOf(entry)], i)
+	}
+	matches := make([]int, len(newArray))
+	matched := make([]bool, len(oldArray))
+	for i, entry := range newArray {
+		matches[i] = -1
+		if indexes := oldIndexes[keyOf(entry)]; len(indexes) > 0 {
+			matches[i] = indexes[0]
+			matched[indexes[0]] = true
+			oldIndexes[keyOf(entry)] = indexes[1:]
+		}
+	}
+
+	for i, entry := range oldArray {
+		if !matched[i] {
+			*changes = append(*changes, tomlChange{op: "remove", path: path.join(indexElement(i)), oldValue: entry})
+		}
+	}
+	for i, entry := range newArray {
+		indexPath := path.join(indexElement(i))
+		if matches[i] < 0 {
+			*changes = append(*changes, tomlChange{op: "add", path: indexPath, newValue: entry})
+			continue
+		}
+		d.diff(indexPath, oldArray[matches[i]], entry, changes)
+	}
+}
+
+// asDiffTable returns the value as a table, unless it is a tagged value,
+// which is compared as a whole.
+func asDiffTable(value any) (map[string]any, bool) {
+	table, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if _, tagged := asTaggedValue(table); tagged {
+		return nil, false
+	}
+	return table, true
+}
+
+// hasKeyField returns whether every entry of an array of tables has the key.
+func hasKeyField(entries []any, keyField string) bool {
+	for _, entry := range entries {
+		if _, ok := entry.(map[string]any)[keyField]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// formatTomlChanges formats changes in the style of a unified diff, with the
+// values written as inline TOML.
+func formatTomlChanges(changes []tomlChange) (string, error) {
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	options := defaultTomlEncoderOptions()
+	options.inexactNumbers = "round"
+	e := &tomlEncoder{options: options}
+	e.b = append(e.b, "--- old\n+++ new\n"...)
+	for _, change := range changes {
+		for _, line := range []struct {
+			prefix byte
+			value  any
+		}{{'-', change.oldValue}, {'+', change.newValue}} {
+			if line.value == nil {
+				continue
+			}
+			e.b = append(e.b, line.prefix)
+			e.b = append(e.b, change.path.String()...)
+			e.b = append(e.b, " = "...)
+			if err := e.encodeValue(change.path, line.value, 0); err != nil {
+				return "", err
+			}
+			e.b = append(e.b, '\n')
+		}
+	}
+	return string(e.b), nil
+}
+
+// convertTomlChangesToTerraformType converts changes to a tuple of objects.
+// The missing value of an added or removed value is a null of the type of the
+// other value.
+func convertTomlChangesToTerraformType(ctx context.Context, changes []tomlChange) (attr.Value, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	elementTypes := make([]attr.Type, 0, len(changes))
+	elementValues := make([]attr.Value, 0, len(changes))
+	for _, change := range changes {
+		var oldType, newType attr.Type
+		var oldValue, newValue attr.Value
+		if change.oldValue != nil {
+			var valueDiags diag.Diagnostics
+			oldType, oldValue, valueDiags = convertValueToTerraformType(change.path, change.oldValue)
+			diags.Append(valueDiags...)
+		}
+		if change.newValue != nil {
+			var valueDiags diag.Diagnostics
+			newType, newValue, valueDiags = convertValueToTerraformType(change.path, change.newValue)
+			diags.Append(valueDiags...)
+		}
+		if diags.HasError() {
+			return nil, diags
+		}
+		if oldValue == nil {
+			oldType, oldValue = newType, nullTerraformValue(ctx, newType, &diags)
+		}
+		if newValue == nil {
+			newType, newValue = oldType, nullTerraformValue(ctx, oldType, &diags)
+		}
+
+		attributeTypes := map[string]attr.Type{
+			"op":   types.StringType,
+			"path": types.StringType,
+			"old":  oldType,
+			"new":  newType,
+		}
+		element, elementDiags := types.ObjectValue(attributeTypes, map[string]attr.Value{
+			"op":   types.StringValue(change.op),
+			"path": types.StringValue(change.path.String()),
+			"old":  oldValue,
+			"new":  newValue,
+		})
+		diags.Append(elementDiags...)
+		elementTypes = append(elementTypes, types.ObjectType{AttrTypes: attributeTypes})
+		elementValues = append(elementValues, element)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	result, tupleDiags := types.TupleValue(elementTypes, elementValues)
+	diags.Append(tupleDiags...)
+	return result, diags
+}
+
+// nullTerraformValue returns a null value of the given type.
+func nullTerraformValue(ctx context.Context, t attr.Type, diags *diag.Diagnostics) attr.Value {
+	value, err := t.ValueFromTerraform(ctx, tftypes.NewValue(t.TerraformType(ctx), nil))
+	if err != nil {
+		diags.AddError("Invalid null value", fmt.Sprintf("Unable to create a null value of type %s: %s", t, err))
+	}
+	return value
+}
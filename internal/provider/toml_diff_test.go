@@ -0,0 +1,264 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	testDiffOldDocument = `
+name = "example"
+version = "0.1.0"
+ports = [80, 443]
+
+[limits]
+cpu = 1
+memory = "1Gi"
+
+[[bin]]
+name = "first"
+path = "src/first.rs"
+
+[[bin]]
+name = "second"
+path = "src/second.rs"
+`
+
+	testDiffNewDocument = `
+name = "example"
+version = "0.2.0"
+ports = [80, 8443]
+
+[limits]
+cpu = 1.0
+gpu = 1
+
+[[bin]]
+name = "second"
+path = "src/main.rs"
+
+[[bin]]
+name = "third"
+path = "src/third.rs"
+`
+
+	testDiffConfig = `
+output "changes" {
+	value = provider::toml::diff("a = 1\nb = 'x'\n[c]\nd = true", "a = 2\n[c]\nd = true\ne = [1, 2]")
+}
+
+output "text" {
+	value = provider::toml::diff("a = 1\nb = 'x'", "a = 2\nc = { d = true }", { output = "text" })
+}
+
+output "equal" {
+	value = provider::toml::diff("a = 1 # A comment.", "a = 1.0", { output = "text" })
+}
+`
+
+	testDiffExpectedText = `--- old
++++ new
+-a = 1
++a = 2
+-b = 'x'
++c = {d = true}
+`
+
+	testDiffUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "test" {
+	value = provider::toml::diff("a = 1", { a = { b = terraform_data.computed.output } }, { output = "text" })
+}
+`
+
+	testDiffInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::diff("a = 1", "a = 2", { output = "html" })
+}
+`
+
+	testDiffInvalidDocumentConfig = `
+output "test" {
+	value = provider::toml::diff("a = 1", "a = [1")
+}
+`
+)
+
+func TestTomlDiffer(t *testing.T) {
+	testCases := map[string]struct {
+		keyField string
+		expected string
+	}{
+		"by index": {
+			expected: `--- old
++++ new
+-bin[0].name = 'first'
++bin[0].name = 'second'
+-bin[0].path = 'src/first.rs'
++bin[0].path = 'src/main.rs'
+-bin[1].name = 'second'
++bin[1].name = 'third'
+-bin[1].path = 'src/second.rs'
++bin[1].path = 'src/third.rs'
++limits.gpu = 1
+-limits.memory = '1Gi'
+-ports = [80, 443]
++ports = [80, 8443]
+-version = '0.1.0'
++version = '0.2.0'
+`,
+		},
+		"by key": {
+			keyField: "name",
+			expected: `--- old
++++ new
+-bin[0] = {name = 'first', path = 'src/first.rs'}
+-bin[0].path = 'src/second.rs'
++bin[0].path = 'src/main.rs'
++bin[1] = {name = 'third', path = 'src/third.rs'}
++limits.gpu = 1
+-limits.memory = '1Gi'
+-ports = [80, 443]
++ports = [80, 8443]
+-version = '0.1.0'
++version = '0.2.0'
+`,
+		},
+		"missing key": {
+			keyField: "id",
+			expected: `--- old
++++ new
+-bin[0].name = 'first'
++bin[0].name = 'second'
+-bin[0].path = 'src/first.rs'
++bin[0].path = 'src/main.rs'
+-bin[1].name = 'second'
++bin[1].name = 'third'
+-bin[1].path = 'src/second.rs'
++bin[1].path = 'src/third.rs'
++limits.gpu = 1
+-limits.memory = '1Gi'
+-ports = [80, 443]
++ports = [80, 8443]
+-version = '0.1.0'
++version = '0.2.0'
+`,
+		},
+	}
+
+	var oldDocument, newDocument map[string]any
+	if err := toml.Unmarshal([]byte(testDiffOldDocument), &oldDocument); err != nil {
+		t.Fatal(err)
+	}
+	if err := toml.Unmarshal([]byte(testDiffNewDocument), &newDocument); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var changes []tomlChange
+			tomlDiffer{keyField: testCase.keyField}.diff(nil, oldDocument, newDocument, &changes)
+			actual, err := formatTomlChanges(changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestDiffFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testDiffInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`output\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config:      testDiffInvalidDocumentConfig,
+				ExpectError: regexp.MustCompile(`The\s+TOML\s+document\s+cannot\s+be\s+decoded`),
+			},
+			{
+				Config: testDiffConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"changes",
+						knownvalue.TupleExact([]knownvalue.Check{
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"op":   knownvalue.StringExact("change"),
+								"path": knownvalue.StringExact("a"),
+								"old":  knownvalue.Int64Exact(1),
+								"new":  knownvalue.Int64Exact(2),
+							}),
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"op":   knownvalue.StringExact("remove"),
+								"path": knownvalue.StringExact("b"),
+								"old":  knownvalue.StringExact("x"),
+								"new":  knownvalue.Null(),
+							}),
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"op":   knownvalue.StringExact("add"),
+								"path": knownvalue.StringExact("c.e"),
+								"old":  knownvalue.Null(),
+								"new": knownvalue.TupleExact([]knownvalue.Check{
+									knownvalue.Int64Exact(1),
+									knownvalue.Int64Exact(2),
+								}),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"text",
+						knownvalue.StringExact(testDiffExpectedText),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"equal",
+						knownvalue.StringExact(""),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDiffFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testDiffUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("test"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.StringExact("--- old\n+++ new\n-a = 1\n+a = {b = 'value'}\n"),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,1068 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// tomlDocument is a parsed TOML document that can be edited in place.
+//
+// Unlike decoding a document and encoding it again, edits only touch the bytes
+// of what is being changed, so the comments, ordering and whitespace of the
+// rest of the document are preserved.
+type tomlDocument struct {
+	data []byte
+	// decoded is the decoded content of the document.
+	decoded any
+	// sections of the document, in order. The first section is always the
+	// root table, and sections cover the whole document.
+	sections []*tomlSection
+}
+
+// tomlSection is either the root table of a document, or a table or array of
+// tables header along with the key-values that follow it.
+type tomlSection struct {
+	// path is the absolute path of the table, including the index of the entry
+	// for arrays of tables. It is empty for the root table.
+	path  tomlPath
+	array bool
+	// header is the header as written, e.g. `[[bin]]`. It is empty for the
+	// root table.
+	header string
+	// start and end delimit the whole section, including any comments directly
+	// preceding the header.
+	start, end int
+	// headerStart and headerEnd delimit the line of the header, including the
+	// newline.
+	headerStart, headerEnd int
+	keyValues              []*tomlKeyValue
+}
+
+// tomlKeyValue is a key-value expression within a section.
+type tomlKeyValue struct {
+	// key is the possibly dotted key, relative to the section.
+	key tomlPath
+	// start and end delimit the lines of the key-value, including indentation,
+	// any trailing comment and the newline.
+	start, end int
+	value      *tomlValueNode
+}
+
+// tomlValueNode describes the location of a value within the document.
+type tomlValueNode struct {
+	kind       unstable.Kind
+	start, end int
+	// entries are the key-values of an inline table, or the elements of an array.
+	entries []*tomlValueEntry
+}
+
+// tomlValueEntry is a key-value of an inline table, or an element of an array.
+type tomlValueEntry struct {
+	// key is the possibly dotted key of the key-value, and empty for array elements.
+	key tomlPath
+	// start is the offset of the key, or of the value for array elements.
+	start int
+	value *tomlValueNode
+}
+
+// parseTomlDocument parses a TOML document for editing.
+func parseTomlDocument(data []byte) (*tomlDocument, error) {
+	// The parser only checks the syntax of the document, so decode it first to
+	// report any other errors, such as duplicate keys.
+	var decoded any
+	if err := toml.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	doc := &tomlDocument{data: data, decoded: decoded}
+	current := &tomlSection{}
+	doc.sections = append(doc.sections, current)
+	arrayTables := make(map[string]int)
+
+	p := unstable.Parser{}
+	p.Reset(data)
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			section, err := doc.parseHeader(expr, arrayTables)
+			if err != nil {
+				return nil, err
+			}
+			current.end = section.start
+			current = section
+			doc.sections = append(doc.sections, section)
+		case unstable.KeyValue:
+			keyValue, err := doc.parseKeyValue(expr)
+			if err != nil {
+				return nil, err
+			}
+			current.keyValues = append(current.keyValues, keyValue)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+	current.end = len(data)
+
+	return doc, nil
+}
+
+func (d *tomlDocument) parseHeader(expr *unstable.Node, arrayTables map[string]int) (*tomlSection, error) {
+	var header []string
+	keyStart, keyEnd := -1, 0
+	it := expr.Key()
+	for it.Next() {
+		key := it.Node()
+		if keyStart < 0 {
+			keyStart = int(key.Raw.Offset)
+		}
+		keyEnd = int(key.Raw.Offset + key.Raw.Length)
+		header = append(header, string(key.Data))
+	}
+
+	// Resolve the header to an absolute path, which means including the index
+	// of the last entry of any array of tables it refers to.
+	var path tomlPath
+	for i, key := range header {
+		path = append(path, keyElement(key))
+		if i < len(header)-1 {
+			if count, ok := arrayTables[path.String()]; ok {
+				path = append(path, indexElement(count-1))
+			}
+		}
+	}
+	array := expr.Kind == unstable.ArrayTable
+	if array {
+		count := arrayTables[path.String()]
+		arrayTables[path.String()] = count + 1
+		path = append(path, indexElement(count))
+	}
+
+	open := bytes.LastIndexByte(d.data[:keyStart], '[')
+	if array {
+		open--
+	}
+	headerEnd := d.skipWhitespace(keyEnd)
+	if array {
+		headerEnd++
+	}
+	if open < 0 || headerEnd >= len(d.data) || d.data[headerEnd] != ']' {
+		return nil, fmt.Errorf("unable to locate header of table %s", path)
+	}
+
+	headerStart := d.lineStart(open)
+	return &tomlSection{
+		path:        path,
+		array:       array,
+		header:      string(d.data[open : headerEnd+1]),
+		start:       d.leadingCommentsStart(headerStart),
+		headerStart: headerStart,
+		headerEnd:   d.lineEnd(headerEnd + 1),
+	}, nil
+}
+
+func (d *tomlDocument) parseKeyValue(expr *unstable.Node) (*tomlKeyValue, error) {
+	key, keyStart, valueStart, err := d.parseKey(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := d.parseValue(expr.Value(), valueStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tomlKeyValue{
+		key:   key,
+		start: d.lineStart(keyStart),
+		end:   d.lineEnd(value.end),
+		value: value,
+	}, nil
+}
+
+// parseKey returns the key of a key-value node, along with the offsets of the
+// key and of the value.
+func (d *tomlDocument) parseKey(expr *unstable.Node) (tomlPath, int, int, error) {
+	var key tomlPath
+	keyStart, keyEnd := -1, 0
+	it := expr.Key()
+	for it.Next() {
+		node := it.Node()
+		if keyStart < 0 {
+			keyStart = int(node.Raw.Offset)
+		}
+		keyEnd = int(node.Raw.Offset + node.Raw.Length)
+		key = append(key, keyElement(string(node.Data)))
+	}
+
+	separator := d.skipWhitespace(keyEnd)
+	if separator >= len(d.data) || d.data[separator] != '=' {
+		return nil, 0, 0, fmt.Errorf("unable to locate value of key %s", key)
+	}
+	return key, keyStart, d.skipWhitespace(separator + 1), nil
+}
+
+// parseValue determines the location of a value node starting at the given offset.
+func (d *tomlDocument) parseValue(node *unstable.Node, start int) (*tomlValueNode, error) {
+	value := &tomlValueNode{kind: node.Kind, start: start}
+
+	switch node.Kind {
+	case unstable.String:
+		value.end = start + int(node.Raw.Length)
+	case unstable.Bool, unstable.Integer, unstable.Float,
+		unstable.LocalDate, unstable.LocalTime, unstable.LocalDateTime, unstable.DateTime:
+		// Data references the raw bytes of the input for all of these kinds.
+		value.end = start + len(node.Data)
+	case unstable.Array:
+		pos := start + 1
+		it := node.Children()
+		for it.Next() {
+			child := it.Node()
+			if child.Kind == unstable.Comment {
+				continue
+			}
+			pos = d.skipFiller(pos)
+			element, err := d.parseValue(child, pos)
+			if err != nil {
+				return nil, err
+			}
+			value.entries = append(value.entries, &tomlValueEntry{start: pos, value: element})
+			pos = element.end
+		}
+		pos = d.skipFiller(pos)
+		if pos >= len(d.data) || d.data[pos] != ']' {
+			return nil, fmt.Errorf("unable to locate end of array at offset %d", start)
+		}
+		value.end = pos + 1
+	case unstable.InlineTable:
+		pos := start + 1
+		it := node.Children()
+		for it.Next() {
+			child := it.Node()
+			if child.Kind != unstable.KeyValue {
+				continue
+			}
+			key, keyStart, valueStart, err := d.parseKey(child)
+			if err != nil {
+				return nil, err
+			}
+			element, err := d.parseValue(child.Value(), valueStart)
+			if err != nil {
+				return nil, err
+			}
+			value.entries = append(value.entries, &tomlValueEntry{key: key, start: keyStart, value: element})
+			pos = element.end
+		}
+		pos = d.skipFiller(pos)
+		if pos >= len(d.data) || d.data[pos] != '}' {
+			return nil, fmt.Errorf("unable to locate end of inline table at offset %d", start)
+		}
+		value.end = pos + 1
+	default:
+		return nil, fmt.Errorf("unexpected %s value at offset %d", node.Kind, start)
+	}
+
+	return value, nil
+}
+
+// skipWhitespace returns the offset of the first non-whitespace byte at or after pos.
+func (d *tomlDocument) skipWhitespace(pos int) int {
+	for pos < len(d.data) && (d.data[pos] == ' ' || d.data[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// skipFiller returns the offset of the first byte at or after pos which isn't
+// whitespace, a newline, a comment or a comma.
+func (d *tomlDocument) skipFiller(pos int) int {
+	for pos < len(d.data) {
+		switch d.data[pos] {
+		case ' ', '\t', '\r', '\n', ',':
+			pos++
+		case '#':
+			for pos < len(d.data) && d.data[pos] != '\n' {
+				pos++
+			}
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// lineStart returns the offset of the start of the line containing pos.
+func (d *tomlDocument) lineStart(pos int) int {
+	return bytes.LastIndexByte(d.data[:pos], '\n') + 1
+}
+
+// lineEnd returns the offset just after the end of the line containing pos,
+// including the newline.
+func (d *tomlDocument) lineEnd(pos int) int {
+	end := bytes.IndexByte(d.data[pos:], '\n')
+	if end < 0 {
+		return len(d.data)
+	}
+	return pos + end + 1
+}
+
+// leadingCommentsStart returns the start of any comment lines directly
+// preceding the line starting at pos. Comment lines which start the document
+// describe the whole document rather than the line at pos, so they are not
+// included.
+func (d *tomlDocument) leadingCommentsStart(pos int) int {
+	start := pos
+	for start > 0 {
+		previous := d.lineStart(start - 1)
+		line := bytes.TrimSpace(d.data[previous:start])
+		if len(line) == 0 || line[0] != '#' {
+			break
+		}
+		start = previous
+	}
+	if start == 0 {
+		return pos
+	}
+	return start
+}
+
+// sectionAt returns the section with a header for exactly the given path.
+func (d *tomlDocument) sectionAt(path tomlPath) *tomlSection {
+	if len(path) == 0 {
+		return d.sections[0]
+	}
+	for _, section := range d.sections[1:] {
+		if section.path.equal(path) {
+			return section
+		}
+	}
+	return nil
+}
+
+// keyValueAt returns the key-value that defines either the value at the given
+// path, or a value which contains it.
+func (d *tomlDocument) keyValueAt(path tomlPath) (*tomlSection, *tomlKeyValue) {
+	for _, section := range d.sections {
+		for _, keyValue := range section.keyValues {
+			if path.hasPrefix(section.path.join(keyValue.key...)) {
+				return section, keyValue
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dottedTableSection returns the section in which the table at the given path
+// is defined using dotted keys, if any.
+func (d *tomlDocument) dottedTableSection(path tomlPath) *tomlSection {
+	for _, section := range d.sections {
+		if !path.hasPrefix(section.path) || len(path) == len(section.path) {
+			continue
+		}
+		for _, keyValue := range section.keyValues {
+			keyPath := section.path.join(keyValue.key...)
+			if keyPath.hasPrefix(path) && len(keyPath) > len(path) {
+				return section
+			}
+		}
+	}
+	return nil
+}
+
+// hasSectionsWithin returns whether any table headers are for the given path,
+// or for a table within it.
+func (d *tomlDocument) hasSectionsWithin(path tomlPath) bool {
+	for _, section := range d.sections[1:] {
+		if section.path.hasPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastSectionWithin returns the last section for the given path or a table
+// within it.
+func (d *tomlDocument) lastSectionWithin(path tomlPath) *tomlSection {
+	var last *tomlSection
+	for _, section := range d.sections {
+		if section.path.hasPrefix(path) {
+			last = section
+		}
+	}
+	return last
+}
+
+// exists returns whether the given path refers to a table, an array of tables or
+// a value within the document.
+func (d *tomlDocument) exists(path tomlPath) bool {
+	if len(path) == 0 || d.hasSectionsWithin(path) || d.dottedTableSection(path) != nil {
+		return true
+	}
+	_, keyValue := d.keyValueAt(path)
+	if keyValue == nil {
+		return false
+	}
+	_, _, node := d.descend(path)
+	return node != nil
+}
+
+// descend finds the value node at the given path, when it is defined by a
+// key-value. It returns the containing node, the index of the entry within it,
+// and the node itself. The containing node is nil when the path refers to the
+// value of the key-value itself.
+func (d *tomlDocument) descend(path tomlPath) (*tomlValueNode, int, *tomlValueNode) {
+	section, keyValue := d.keyValueAt(path)
+	if keyValue == nil {
+		return nil, 0, nil
+	}
+
+	rest := path[len(section.path)+len(keyValue.key):]
+	var parent *tomlValueNode
+	index := 0
+	node := keyValue.value
+	for len(rest) > 0 {
+		parent, index, node = node, -1, nil
+		for i, entry := range parent.entries {
+			if rest[0].isIndex && i == rest[0].index {
+				index, node, rest = i, entry.value, rest[1:]
+				break
+			}
+			if !rest[0].isIndex && len(entry.key) > 0 && rest.hasPrefix(entry.key) {
+				index, node, rest = i, entry.value, rest[len(entry.key):]
+				break
+			}
+		}
+		if node == nil {
+			return nil, 0, nil
+		}
+	}
+	return parent, index, node
+}
+
+// deepestContainer finds the deepest inline table or array defined by a
+// key-value which contains the given path, returning the node and the
+// remainder of the path relative to it.
+func (d *tomlDocument) deepestContainer(path tomlPath) (*tomlValueNode, tomlPath) {
+	section, keyValue := d.keyValueAt(path)
+	if keyValue == nil {
+		return nil, nil
+	}
+
+	rest := path[len(section.path)+len(keyValue.key):]
+	node := keyValue.value
+	for len(rest) > 0 {
+		var next *tomlValueNode
+		for i, entry := range node.entries {
+			if rest[0].isIndex && i == rest[0].index {
+				next, rest = entry.value, rest[1:]
+				break
+			}
+			if !rest[0].isIndex && len(entry.key) > 0 && rest.hasPrefix(entry.key) {
+				next, rest = entry.value, rest[len(entry.key):]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return node, rest
+}
+
+// line returns the line number on which the value at the given path is
+// defined. If the path does not exist, the line of the closest value
+// containing it is returned instead, or 0 for the root table.
+func (d *tomlDocument) line(path tomlPath) int {
+	for ; len(path) > 0; path = path[:len(path)-1] {
+		if _, keyValue := d.keyValueAt(path); keyValue != nil {
+			node, _ := d.deepestContainer(path)
+			return d.lineOf(node.start)
+		}
+		for _, section := range d.sections[1:] {
+			if section.path.hasPrefix(path) {
+				return d.lineOf(section.headerStart)
+			}
+		}
+		if section := d.dottedTableSection(path); section != nil {
+			for _, keyValue := range section.keyValues {
+				if section.path.join(keyValue.key...).hasPrefix(path) {
+					return d.lineOf(keyValue.value.start)
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// tomlKeyPosition is where a key, table or array element is first defined
+// within a document.
+type tomlKeyPosition struct {
+	// offset is the offset of the key, of the table header, or of the value
+	// for array elements.
+	offset int
+	// section is the section in which it is defined.
+	section *tomlSection
+}
+
+// positions returns where every path within the document is first defined,
+// indexed by the formatted path. Tables which are only defined implicitly,
+// by dotted keys or by the headers of tables within them, are located at the
+// first key or header which defines them.
+func (d *tomlDocument) positions() map[string]tomlKeyPosition {
+	positions := make(map[string]tomlKeyPosition)
+	explicit := make(map[string]bool)
+	define := func(path tomlPath, position tomlKeyPosition, implicit int) {
+		for i := len(path) - implicit; i <= len(path); i++ {
+			key := path[:i].String()
+			if _, ok := positions[key]; !ok {
+				positions[key] = position
+			}
+		}
+	}
+
+	for _, section := range d.sections {
+		if len(section.path) > 0 {
+			position := tomlKeyPosition{offset: d.skipWhitespace(section.headerStart), section: section}
+			// Tables may be defined explicitly after tables within them.
+			if key := section.path.String(); !explicit[key] {
+				explicit[key] = true
+				positions[key] = position
+			}
+			define(section.path, position, len(section.path)-1)
+		}
+
+		for _, keyValue := range section.keyValues {
+			path := section.path.join(keyValue.key...)
+			position := tomlKeyPosition{offset: d.skipWhitespace(keyValue.start), section: section}
+			define(path, position, len(keyValue.key)-1)
+			d.valuePositions(path, keyValue.value, section, define)
+		}
+	}
+
+	return positions
+}
+
+// valuePositions defines the positions of the keys of an inline table, or the
+// elements of an array, and of any values nested within them.
+func (d *tomlDocument) valuePositions(path tomlPath, value *tomlValueNode, section *tomlSection, define func(tomlPath, tomlKeyPosition, int)) {
+	for i, entry := range value.entries {
+		position := tomlKeyPosition{offset: entry.start, section: section}
+		entryPath := path.join(indexElement(i))
+		implicit := 0
+		if value.kind == unstable.InlineTable {
+			entryPath = path.join(entry.key...)
+			implicit = len(entry.key) - 1
+		}
+		define(entryPath, position, implicit)
+		d.valuePositions(entryPath, entry.value, section, define)
+	}
+}
+
+// lineOf returns the line number of the given offset.
+func (d *tomlDocument) lineOf(pos int) int {
+	return bytes.Count(d.data[:pos], []byte("\n")) + 1
+}
+
+// replace returns the document content with the given range replaced.
+func (d *tomlDocument) replace(start, end int, text string) []byte {
+	result := make([]byte, 0, len(d.data)-(end-start)+len(text))
+	result = append(result, d.data[:start]...)
+	result = append(result, text...)
+	return append(result, d.data[end:]...)
+}
+
+// insertLines returns the document content with the given lines inserted at
+// pos, which must be the start of a line or the end of the document.
+func (d *tomlDocument) insertLines(pos int, lines string) []byte {
+	if pos > 0 && d.data[pos-1] != '\n' {
+		lines = "\n" + lines
+	}
+	return d.replace(pos, pos, lines)
+}
+
+// Delete removes the value, table or array of tables at the given path.
+// It returns false if there is nothing at the given path.
+func (d *tomlDocument) Delete(path tomlPath) ([]byte, bool, error) {
+	if len(path) == 0 {
+		return nil, false, fmt.Errorf("the root table cannot be deleted")
+	}
+
+	if section, keyValue := d.keyValueAt(path); keyValue != nil {
+		var content []byte
+		if len(path) == len(section.path)+len(keyValue.key) {
+			content = d.removeRanges([][2]int{{keyValue.start, keyValue.end}})
+		} else {
+			parent, index, node := d.descend(path)
+			if node == nil {
+				return d.data, false, nil
+			}
+			content = d.removeEntry(parent, index)
+		}
+		content, err := d.checked(content)
+		return content, true, err
+	}
+
+	// The path refers to a table or array of tables, which may be defined by
+	// any number of headers and dotted keys.
+	var ranges [][2]int
+	for _, section := range d.sections {
+		if len(section.path) > 0 && section.path.hasPrefix(path) {
+			ranges = append(ranges, [2]int{section.start, section.end})
+			continue
+		}
+		for _, keyValue := range section.keyValues {
+			if section.path.join(keyValue.key...).hasPrefix(path) {
+				ranges = append(ranges, [2]int{keyValue.start, keyValue.end})
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return d.data, false, nil
+	}
+	content, err := d.checked(d.removeRanges(ranges))
+	return content, true, err
+}
+
+// removeRanges removes the given sorted, non-overlapping ranges of lines.
+func (d *tomlDocument) removeRanges(ranges [][2]int) []byte {
+	result := d.data
+	for i := len(ranges) - 1; i >= 0; i-- {
+		start, end := ranges[i][0], ranges[i][1]
+		if end == len(result) {
+			// Avoid leaving blank lines at the end of the document.
+			for start > 0 && len(bytes.TrimSpace(result[d.lineStartIn(result, start-1):start])) == 0 {
+				start = d.lineStartIn(result, start-1)
+			}
+		}
+		result = append(result[:start:start], result[end:]...)
+	}
+	return result
+}
+
+func (d *tomlDocument) lineStartIn(data []byte, pos int) int {
+	return bytes.LastIndexByte(data[:pos], '\n') + 1
+}
+
+// removeEntry removes an entry from an inline table or array, along with its separator.
+func (d *tomlDocument) removeEntry(parent *tomlValueNode, index int) []byte {
+	entries := parent.entries
+	switch {
+	case len(entries) == 1:
+		return d.replace(parent.start+1, parent.end-1, "")
+	case index < len(entries)-1:
+		return d.replace(entries[index].start, entries[index+1].start, "")
+	default:
+		return d.replace(entries[index-1].value.end, entries[index].value.end, "")
+	}
+}
+
+// Set sets the value at the given path, creating any missing tables. The value
+// must be one returned by convertFromTerraformType.
+func (d *tomlDocument) Set(path tomlPath, value any) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("the root table cannot be replaced")
+	}
+	if value == nil {
+		return nil, fmt.Errorf("null values cannot be represented in TOML")
+	}
+
+	// Values defined by key-values, including those nested in inline tables and
+	// arrays, are replaced where they are.
+	if _, _, node := d.descend(path); node != nil {
+		if str, ok := value.(string); ok && node.kind == unstable.String {
+			text := formatTomlStringLike(string(d.data[node.start:node.end]), str)
+			return d.checked(d.replace(node.start, node.end, text))
+		}
+		text, err := formatTomlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return d.checked(d.replace(node.start, node.end, text))
+	}
+	if container, rest := d.deepestContainer(path); container != nil {
+		return d.insertIntoContainer(container, rest, value)
+	}
+
+	if d.exists(path) {
+		if table, ok := asTomlTable(value); ok && d.arrayTableLength(path) == 0 {
+			return d.updateTable(path, table)
+		}
+
+		// Anything else defined by headers is removed and then recreated.
+		content, _, err := d.Delete(path)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := parseTomlDocument(content)
+		if err != nil {
+			return nil, err
+		}
+		return doc.insert(path, value)
+	}
+
+	return d.insert(path, value)
+}
+
+// insertIntoContainer inserts a value into an inline table or array.
+func (d *tomlDocument) insertIntoContainer(container *tomlValueNode, rest tomlPath, value any) ([]byte, error) {
+	switch {
+	case container.kind == unstable.InlineTable && !rest[0].isIndex:
+		text, err := formatTomlValue(wrapValue(rest[1:], value))
+		if err != nil {
+			return nil, err
+		}
+		entry := formatTomlKey(rest[0].key) + " = " + text
+		if len(container.entries) == 0 {
+			return d.checked(d.replace(container.start, container.end, "{"+entry+"}"))
+		}
+		last := container.entries[len(container.entries)-1]
+		return d.checked(d.replace(last.value.end, last.value.end, ", "+entry))
+	case container.kind == unstable.Array && rest[0].isIndex && rest[0].index == len(container.entries) && len(rest) == 1:
+		text, err := formatTomlValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(container.entries) == 0 {
+			return d.checked(d.replace(container.start, container.end, "["+text+"]"))
+		}
+		last := container.entries[len(container.entries)-1]
+		return d.checked(d.replace(last.value.end, last.value.end, ", "+text))
+	case container.kind == unstable.Array && rest[0].isIndex:
+		return nil, fmt.Errorf("index %d is out of range for an array of length %d", rest[0].index, len(container.entries))
+	case container.kind == unstable.Array:
+		return nil, fmt.Errorf("key %s cannot be set on an array", formatTomlKey(rest[0].key))
+	case container.kind == unstable.InlineTable:
+		return nil, fmt.Errorf("index %d cannot be set on a table", rest[0].index)
+	default:
+		return nil, fmt.Errorf("cannot set %s within a %s value", rest, strings.ToLower(container.kind.String()))
+	}
+}
+
+// updateTable updates an existing table key by key, so that the formatting of
+// keys which are unchanged is preserved.
+func (d *tomlDocument) updateTable(path tomlPath, table map[string]any) ([]byte, error) {
+	existing, _ := lookupDecodedValue(d.decoded, path)
+	existingTable, _ := existing.(map[string]any)
+
+	keys := make([]string, 0, len(existingTable)+len(table))
+	for key := range existingTable {
+		keys = append(keys, key)
+	}
+	for key := range table {
+		if _, ok := existingTable[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	doc := d
+	for _, key := range keys {
+		value := table[key]
+		if existingValue, ok := existingTable[key]; ok && reflect.DeepEqual(normalizeDecodedValue(existingValue), value) {
+			continue
+		}
+
+		var content []byte
+		var err error
+		if value == nil {
+			content, _, err = doc.Delete(path.join(keyElement(key)))
+		} else {
+			content, err = doc.Set(path.join(keyElement(key)), value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc, err = parseTomlDocument(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc.data, nil
+}
+
+// insert inserts a value at a path which doesn't exist yet.
+func (d *tomlDocument) insert(path tomlPath, value any) ([]byte, error) {
+	leaf := path[len(path)-1]
+	parentPath := path[:len(path)-1]
+
+	if leaf.isIndex {
+		// Only appending an entry to an array of tables is supported here, since
+		// inline arrays are handled as containers.
+		table, ok := asTomlTable(value)
+		if !ok {
+			return nil, fmt.Errorf("entries of an array of tables must be tables")
+		}
+		count := d.arrayTableLength(parentPath)
+		if leaf.index != count {
+			return nil, fmt.Errorf("index %d is out of range for an array of length %d", leaf.index, count)
+		}
+		if count == 0 && d.exists(parentPath) {
+			return nil, fmt.Errorf("%s is not an array of tables", parentPath)
+		}
+		return d.insertSection(path, true, table)
+	}
+
+	// Find the deepest table that exists.
+	ancestor := parentPath
+	for !d.exists(ancestor) {
+		ancestor = ancestor[:len(ancestor)-1]
+	}
+	if ancestor.equal(parentPath) && d.arrayTableLength(parentPath) > 0 {
+		return nil, fmt.Errorf("%s is an array of tables, so an index is required", parentPath)
+	}
+
+	// Tables defined by dotted keys can only be extended with dotted keys.
+	if section := d.dottedTableSection(ancestor); section != nil && d.sectionAt(ancestor) == nil {
+		return d.insertKeyValue(section, path[len(section.path):], value)
+	}
+
+	// Tables are usually only written inline when nested within other tables,
+	// so top-level tables get their own headers.
+	_, isTable := asTomlTable(value)
+	tables, isArrayOfTables := asArrayOfTables(value)
+	if ancestor.equal(parentPath) && (len(parentPath) > 0 || !isTable && !isArrayOfTables) {
+		if section := d.sectionAt(parentPath); section != nil {
+			return d.insertKeyValue(section, path[len(parentPath):], value)
+		}
+	}
+
+	if !d.isAddressable(parentPath) {
+		// A header for the table would refer to a different entry of an array of
+		// tables, so fall back to using dotted keys in the closest section.
+		section := d.sectionAt(ancestor)
+		if section == nil {
+			return nil, fmt.Errorf("unable to create table %s", parentPath)
+		}
+		return d.insertKeyValue(section, path[len(ancestor):], value)
+	}
+
+	if isArrayOfTables {
+		doc := d
+		for i, table := range tables {
+			content, err := doc.insertSection(path.join(indexElement(i)), true, table)
+			if err != nil {
+				return nil, err
+			}
+			doc, err = parseTomlDocument(content)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return doc.data, nil
+	}
+	if table, ok := asTomlTable(value); ok {
+		return d.insertSection(path, false, table)
+	}
+	return d.insertSection(parentPath, false, map[string]any{leaf.key: value})
+}
+
+// arrayTableLength returns the number of entries in the array of tables at the
+// given path.
+func (d *tomlDocument) arrayTableLength(path tomlPath) int {
+	count := 0
+	for _, section := range d.sections[1:] {
+		if section.array && len(section.path) == len(path)+1 && section.path.hasPrefix(path) {
+			count++
+		}
+	}
+	return count
+}
+
+// isAddressable returns whether a table header can refer to the given path,
+// which is only the case when any index refers to the last entry of an array
+// of tables.
+func (d *tomlDocument) isAddressable(path tomlPath) bool {
+	for i, element := range path {
+		if element.isIndex && element.index != d.arrayTableLength(path[:i])-1 {
+			return false
+		}
+	}
+	return true
+}
+
+// insertKeyValue inserts a key-value line at the end of the key-values of a section.
+func (d *tomlDocument) insertKeyValue(section *tomlSection, key tomlPath, value any) ([]byte, error) {
+	text, err := formatTomlValue(value)
+	if err != nil {
+		return nil, err
+	}
+	line := section.indentation(d) + key.String() + " = " + text + "\n"
+
+	pos := section.headerEnd
+	if len(section.keyValues) > 0 {
+		pos = section.keyValues[len(section.keyValues)-1].end
+	}
+	return d.checked(d.insertLines(pos, line))
+}
+
+// insertSection inserts a new table or array of tables header with the given
+// key-values. It is inserted after any existing sections for the parent table.
+func (d *tomlDocument) insertSection(path tomlPath, array bool, table map[string]any) ([]byte, error) {
+	body, err := formatTableBody(table, "")
+	if err != nil {
+		return nil, err
+	}
+
+	header := "[" + path.keys().String() + "]"
+	if array {
+		header = "[" + header + "]"
+	}
+	text := header + "\n" + body
+
+	// Insert the section after the last section within the closest ancestor,
+	// but before any section which is for the table itself.
+	pos := len(d.data)
+	for ancestor := path[:len(path)-1]; len(ancestor) > 0; ancestor = ancestor[:len(ancestor)-1] {
+		if last := d.lastSectionWithin(ancestor); last != nil {
+			pos = last.end
+			break
+		}
+	}
+	for _, section := range d.sections[1:] {
+		if section.path.hasPrefix(path) && section.start < pos && !array {
+			pos = section.start
+			break
+		}
+	}
+
+	text = d.blankLineBefore(pos) + text
+	if pos < len(d.data) {
+		text += "\n"
+	}
+	return d.checked(d.insertLines(pos, text))
+}
+
+// blankLineBefore returns the newline needed to separate content inserted at
+// pos from any preceding content with a blank line.
+func (d *tomlDocument) blankLineBefore(pos int) string {
+	if len(bytes.TrimSpace(d.data[:pos])) == 0 {
+		return ""
+	}
+	if d.data[pos-1] != '\n' {
+		return "\n"
+	}
+	if len(bytes.TrimSpace(d.data[d.lineStart(pos-1):pos])) == 0 {
+		return ""
+	}
+	return "\n"
+}
+
+// indentation returns the indentation used for the key-values of the section.
+func (s *tomlSection) indentation(d *tomlDocument) string {
+	if len(s.keyValues) == 0 {
+		return ""
+	}
+	start := s.keyValues[len(s.keyValues)-1].start
+	return string(d.data[start:d.skipWhitespace(start)])
+}
+
+// checked verifies that an edited document is still valid TOML.
+func (d *tomlDocument) checked(content []byte) ([]byte, error) {
+	var decoded any
+	if err := toml.Unmarshal(content, &decoded); err != nil {
+		return nil, fmt.Errorf("editing the document would make it invalid: %w", err)
+	}
+	return content, nil
+}
+
+// formatTomlValue formats a value returned by convertFromTerraformType as an
+// inline TOML value.
+func formatTomlValue(value any) (string, error) {
+	if value == nil {
+		return "", fmt.Errorf("null values cannot be represented in TOML")
+	}
+
+	e := &tomlEncoder{options: defaultTomlEncoderOptions()}
+	if err := e.encodeValue(nil, value, 0); err != nil {
+		return "", err
+	}
+	return string(e.b), nil
+}
+
+// formatTomlStringLike formats a string in the style of the string it
+// replaces: basic, literal, or either of their multiline forms. Where that
+// style cannot represent the string, the closest basic style is used.
+func formatTomlStringLike(original string, value string) string {
+	e := &tomlEncoder{options: defaultTomlEncoderOptions()}
+	switch {
+	case strings.HasPrefix(original, "'''"):
+		e.encodeMultilineString(value)
+	case strings.HasPrefix(original, `"""`):
+		e.options.basicStrings = true
+		e.encodeMultilineString(value)
+	case strings.HasPrefix(original, "'"):
+		e.encodeString(value)
+	default:
+		e.encodeBasicString(value)
+	}
+	return string(e.b)
+}
+
+// formatTableBody formats the key-values of a table as lines, sorted by key.
+func formatTableBody(table map[string]any, indentation string) (string, error) {
+	keys := make([]string, 0, len(table))
+	for key, value := range table {
+		if value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		text, err := formatTomlValue(table[key])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(indentation + formatTomlKey(key) + " = " + text + "\n")
+	}
+	return b.String(), nil
+}
+
+// asArrayOfTables returns the tables of a value which is a non-empty array
+// consisting only of tables.
+func asArrayOfTables(value any) ([]map[string]any, bool) {
+	elements, ok := value.([]any)
+	if !ok || len(elements) == 0 {
+		return nil, false
+	}
+	tables := make([]map[string]any, len(elements))
+	for i, element := range elements {
+		table, ok := asTomlTable(element)
+		if !ok {
+			return nil, false
+		}
+		tables[i] = table
+	}
+	return tables, true
+}
+
+// normalizeDecodedValue converts a value decoded from a TOML document to the
+// form returned by convertFromTerraformType, so that the two can be compared.
+func normalizeDecodedValue(value any) any {
+	_, terraformValue, diags := convertToTerraformType(value)
+	if diags.HasError() {
+		return value
+	}
+	normalizedValue, err := convertFromTerraformType(terraformValue)
+	if err != nil {
+		return value
+	}
+	return normalizedValue
+}
+
+// wrapValue wraps a value in nested tables for each key of the path.
+func wrapValue(path tomlPath, value any) any {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = map[string]any{path[i].key: value}
+	}
+	return value
+}
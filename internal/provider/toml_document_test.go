@@ -0,0 +1,375 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testDocument = `# Package metadata.
+[package]
+name = "example" # The name.
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+
+# Binaries.
+[[bin]]
+name = "first"
+
+[[bin]]
+name = "second"
+`
+
+func TestTomlDocumentSet(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		path     string
+		value    any
+		expected string
+	}{
+		"replace value": {
+			document: testDocument,
+			path:     "package.version",
+			value:    "0.2.0",
+			expected: `# Package metadata.
+[package]
+name = "example" # The name.
+version = "0.2.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+
+# Binaries.
+[[bin]]
+name = "first"
+
+[[bin]]
+name = "second"
+`,
+		},
+		"replace string keeping its style": {
+			document: "a = \"1.0\" # Keep.\nb = 'x'\n",
+			path:     "a",
+			value:    "2.0",
+			expected: "a = \"2.0\" # Keep.\nb = 'x'\n",
+		},
+		"replace literal string": {
+			document: "b = 'x'\n",
+			path:     "b",
+			value:    "y",
+			expected: "b = 'y'\n",
+		},
+		"replace literal string with quote": {
+			document: "b = 'x'\n",
+			path:     "b",
+			value:    "it's",
+			expected: "b = \"it's\"\n",
+		},
+		"replace multiline literal string": {
+			document: "c = '''\nline\n'''\n",
+			path:     "c",
+			value:    "first\nsecond\n",
+			expected: "c = '''\nfirst\nsecond\n'''\n",
+		},
+		"replace multiline basic string": {
+			document: "d = \"\"\"\nline\n\"\"\"\n",
+			path:     "d",
+			value:    "first\nsecond\n",
+			expected: "d = \"\"\"\nfirst\nsecond\n\"\"\"\n",
+		},
+		"replace string with number": {
+			document: "a = \"1\"\n",
+			path:     "a",
+			value:    int64(1),
+			expected: "a = 1\n",
+		},
+		"add tagged date": {
+			document: "[package]\nname = \"example\"\n",
+			path:     "package.released",
+			value:    map[string]any{"__toml_type": "local_date", "value": "2024-04-13"},
+			expected: "[package]\nname = \"example\"\nreleased = 2024-04-13\n",
+		},
+		"replace value in inline table": {
+			document: testDocument,
+			path:     "dependencies.serde.version",
+			value:    "1.1",
+			expected: `# Package metadata.
+[package]
+name = "example" # The name.
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.1", features = ["derive"] }
+
+# Binaries.
+[[bin]]
+name = "first"
+
+[[bin]]
+name = "second"
+`,
+		},
+		"add key to inline table": {
+			document: "a = {b = 1}\n",
+			path:     "a.c",
+			value:    int64(2),
+			expected: "a = {b = 1, c = 2}\n",
+		},
+		"add key to table": {
+			document: testDocument,
+			path:     "package.edition",
+			value:    "2021",
+			expected: `# Package metadata.
+[package]
+name = "example" # The name.
+version = "0.1.0"
+edition = '2021'
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+
+# Binaries.
+[[bin]]
+name = "first"
+
+[[bin]]
+name = "second"
+`,
+		},
+		"add inline table to table": {
+			document: "[dependencies]\nserde = \"1.0\"\n",
+			path:     "dependencies.tokio",
+			value:    map[string]any{"version": "1"},
+			expected: "[dependencies]\nserde = \"1.0\"\ntokio = {version = '1'}\n",
+		},
+		"create table": {
+			document: "name = \"example\"\n",
+			path:     "tool.black.line-length",
+			value:    int64(88),
+			expected: "name = \"example\"\n\n[tool.black]\nline-length = 88\n",
+		},
+		"create top-level table": {
+			document: "name = \"example\"\n",
+			path:     "tool",
+			value:    map[string]any{"enabled": true},
+			expected: "name = \"example\"\n\n[tool]\nenabled = true\n",
+		},
+		"create table next to sibling": {
+			document: "[tool.a]\nx = 1\n\n[other]\ny = 2\n",
+			path:     "tool.b.x",
+			value:    int64(3),
+			expected: "[tool.a]\nx = 1\n\n[tool.b]\nx = 3\n\n[other]\ny = 2\n",
+		},
+		"create implicit table": {
+			document: "[a.b]\nx = 1\n",
+			path:     "a.y",
+			value:    int64(2),
+			expected: "[a]\ny = 2\n\n[a.b]\nx = 1\n",
+		},
+		"extend dotted table": {
+			document: "a.b = 1\n",
+			path:     "a.c",
+			value:    int64(2),
+			expected: "a.b = 1\na.c = 2\n",
+		},
+		"update table key by key": {
+			document: "[t]\n# Keep this.\na = 1 # And this.\nb = 2\n",
+			path:     "t",
+			value:    map[string]any{"a": int64(1), "c": int64(3)},
+			expected: "[t]\n# Keep this.\na = 1 # And this.\nc = 3\n",
+		},
+		"preserve indentation": {
+			document: "[t]\n  a = 1\n",
+			path:     "t.b",
+			value:    int64(2),
+			expected: "[t]\n  a = 1\n  b = 2\n",
+		},
+		"append to array of tables": {
+			document: testDocument,
+			path:     "bin[2]",
+			value:    map[string]any{"name": "third"},
+			expected: `# Package metadata.
+[package]
+name = "example" # The name.
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+
+# Binaries.
+[[bin]]
+name = "first"
+
+[[bin]]
+name = "second"
+
+[[bin]]
+name = 'third'
+`,
+		},
+		"create array of tables": {
+			document: "name = \"example\"\n",
+			path:     "bin",
+			value:    []any{map[string]any{"name": "first"}},
+			expected: "name = \"example\"\n\n[[bin]]\nname = 'first'\n",
+		},
+		"append to inline array": {
+			document: "a = [1, 2]\n",
+			path:     "a[2]",
+			value:    int64(3),
+			expected: "a = [1, 2, 3]\n",
+		},
+		"no trailing newline": {
+			document: "a = 1",
+			path:     "b",
+			value:    int64(2),
+			expected: "a = 1\nb = 2\n",
+		},
+		"empty document": {
+			document: "",
+			path:     "a",
+			value:    int64(1),
+			expected: "a = 1\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseTomlPath(testCase.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := parseTomlDocument([]byte(testCase.document))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := doc.Set(path, testCase.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(result) != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", result, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestTomlDocumentDelete(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		path     string
+		expected string
+	}{
+		"delete value": {
+			document: "a = 1\n# Comment.\nb = 2\n",
+			path:     "a",
+			expected: "# Comment.\nb = 2\n",
+		},
+		"delete value from inline table": {
+			document: "a = {b = 1, c = 2}\n",
+			path:     "a.b",
+			expected: "a = {c = 2}\n",
+		},
+		"delete last value from inline table": {
+			document: "a = {b = 1, c = 2}\n",
+			path:     "a.c",
+			expected: "a = {b = 1}\n",
+		},
+		"delete only value from inline table": {
+			document: "a = {b = 1}\n",
+			path:     "a.b",
+			expected: "a = {}\n",
+		},
+		"delete table": {
+			document: "a = 1\n\n# About b.\n[b]\nc = 2\n\n[b.d]\ne = 3\n\n[f]\ng = 4\n",
+			path:     "b",
+			expected: "a = 1\n\n[f]\ng = 4\n",
+		},
+		"delete first table after document comments": {
+			document: "# The document.\n[a]\nv = 1\n",
+			path:     "a",
+			expected: "# The document.\n",
+		},
+		"delete first table after document and table comments": {
+			document: "# The document.\n\n# About a.\n[a]\nv = 1\n\n[b]\nw = 2\n",
+			path:     "a",
+			expected: "# The document.\n\n[b]\nw = 2\n",
+		},
+		"delete last table": {
+			document: "a = 1\n\n[b]\nc = 2\n",
+			path:     "b",
+			expected: "a = 1\n",
+		},
+		"delete array table entry": {
+			document: "[[bin]]\nname = \"first\"\n\n[[bin]]\nname = \"second\"\n",
+			path:     "bin[0]",
+			expected: "[[bin]]\nname = \"second\"\n",
+		},
+		"delete dotted table": {
+			document: "a.b = 1\na.c = 2\nd = 3\n",
+			path:     "a",
+			expected: "d = 3\n",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseTomlPath(testCase.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := parseTomlDocument([]byte(testCase.document))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, found, err := doc.Delete(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Fatal("expected path to be found")
+			}
+			if string(result) != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", result, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestTomlDocumentPositions(t *testing.T) {
+	document := "title = \"example\"\nowner.name = \"alice\"\n\n[servers.alpha]\nip = \"10.0.0.1\"\nports = [80, { number = 443 }]\n\n[servers]\nregion = \"eu\"\n\n  [[bin]]\n  name = \"first\"\n"
+	expected := []string{
+		`bin 11:3 [[bin]]`,
+		`bin[0] 11:3 [[bin]]`,
+		`bin[0].name 12:3 [[bin]]`,
+		`owner 2:1 `,
+		`owner.name 2:1 `,
+		`servers 8:1 [servers]`,
+		`servers.alpha 4:1 [servers.alpha]`,
+		`servers.alpha.ip 5:1 [servers.alpha]`,
+		`servers.alpha.ports 6:1 [servers.alpha]`,
+		`servers.alpha.ports[0] 6:10 [servers.alpha]`,
+		`servers.alpha.ports[1] 6:14 [servers.alpha]`,
+		`servers.alpha.ports[1].number 6:16 [servers.alpha]`,
+		`servers.region 9:1 [servers]`,
+		`title 1:1 `,
+	}
+
+	doc, err := parseTomlDocument([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for path, position := range doc.positions() {
+		line, column := tomlPosition(doc.data, position.offset)
+		actual = append(actual, fmt.Sprintf("%s %d:%d %s", path, line, column, position.section.header))
+	}
+	sort.Strings(actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected positions:\n%s\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/pelletier/go-toml/v2"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -34,21 +36,75 @@ func (r EncodeFunction) Definition(_ context.Context, _ function.DefinitionReque
 				"The function maps [Terraform language values](https://developer.hashicorp.com/terraform/language/expressions/types)",
 				"to TOML values in the following way:",
 				"",
-				"| Terraform type | TOML type                                    |",
-				"|----------------|----------------------------------------------|",
-				"| `string`       | `String`                                     |",
-				"| `number`       | `Integer` if whole number, `Float` otherwise |",
-				"| `bool`         | `Boolean`                                    |",
-				"| `list(...)`    | `Array`                                      |",
-				"| `set(...)`     | `Array`                                      |",
-				"| `tuple(...)`   | `Array`                                      |",
-				"| `map(...)`     | `Table`                                      |",
-				"| `object(...)`  | `Table`                                      |",
-				"| Null value     | Absent from result                           |",
+				"| Terraform type | TOML type                                       |",
+				"|----------------|-------------------------------------------------|",
+				"| `string`       | `String`                                        |",
+				"| `number`       | `Integer` if whole number, `Float` otherwise    |",
+				"| `bool`         | `Boolean`                                       |",
+				"| `list(...)`    | `Array`                                         |",
+				"| `set(...)`     | `Array`                                         |",
+				"| `tuple(...)`   | `Array`                                         |",
+				"| `map(...)`     | `Table`                                         |",
+				"| `object(...)`  | `Table`                                         |",
+				"| Null value     | Absent from result, unless `null_policy` is set |",
+				"",
+				"TOML integers are 64-bit, and TOML floats are 64-bit IEEE 754 values. By default, a whole number",
+				"outside the range of a 64-bit integer, or a number with more precision than a 64-bit float can",
+				"hold, such as `1/3`, is rounded to the nearest float, which `encode_report` reports. The",
+				"`inexact_numbers` option below can be used to raise an error instead, or to write them as strings.",
+				"The special float values `inf`, `-inf` and `nan` can be written using the `float` function.",
+				"",
+				"Objects of the form `{ __toml_type = \"local_date\", value = \"2024-04-13\" }`, as returned by `decode`",
+				"with `datetimes = \"tagged\"`, are encoded as native TOML date and time values. Similar objects",
+				"returned by the `float`, `integer`, `multiline`, `datetime`, `local_datetime`, `local_date` and",
+				"`local_time` functions control the exact TOML representation of a value. The `__toml_type` key is",
+				"reserved for these objects, and any other object, such as `{ toml_type = \"x\", value = 1 }`, is",
+				"encoded as a table.",
 				"",
 				"Since the TOML format cannot fully represent all Terraform language types ",
 				"(and vice versa), passing the `encode` result to `decode` will not always ",
 				"produce an identical value.",
+				"",
+				"If any part of the value is unknown, for example because it is computed during apply, the result",
+				"is unknown until the whole value is known.",
+				"",
+				"An optional second argument is an object configuring the layout of the result. It supports the",
+				"following attributes:",
+				"",
+				"| Attribute          | Description                                                                                    |",
+				"|--------------------|------------------------------------------------------------------------------------------------|",
+				"| `table_indent`     | The indentation added for each level of table nesting. Default `\"\"`.                           |",
+				"| `array_indent`     | The indentation of the elements of multiline arrays. Default two spaces.                       |",
+				"| `inline_tables`    | Paths of tables and arrays of tables to write inline, e.g. `[\"package.metadata\"]`.             |",
+				"| `inline_depth`     | The depth below which tables are written inline, e.g. `1` for top-level tables only.           |",
+				"| `multiline_arrays` | Whether to write each element of an array on its own line. Default `false`.                    |",
+				"| `quote_style`      | `literal` (default) to use literal strings where possible, or `basic` to always use basic.     |",
+				"| `trailing_newline` | Whether to end the result with a newline. Default `true`.                                      |",
+				"| `key_order`        | TOML content, such as the original file, whose table and key order to follow.                  |",
+				"| `inexact_numbers`  | How to write numbers TOML cannot represent exactly: `round` (default), `error` or `string`.    |",
+				"| `null_policy`      | How to write null values: `omit` (default), `error`, `empty` or `comment`, as described below. |",
+				"",
+				"Paths in `inline_tables` are written using TOML dotted key syntax, without array indexes.",
+				"Tables within an array of tables are matched regardless of their entry.",
+				"",
+				"By default, keys are written in lexical order. With `key_order`, the keys of each table are written",
+				"in the order they appear in the given content, followed by any other keys in lexical order. This",
+				"allows a decode, modify and encode cycle to keep the original order of a file:",
+				"`provider::toml::encode(merge(provider::toml::decode(file(\"config.toml\")), { ... }), { key_order = file(\"config.toml\") })`.",
+				"As with the default order, key-values are always written before the sub-tables of a table.",
+				"",
+				"TOML has no null value, so `null_policy` controls how null values within the value are written:",
+				"",
+				"| Policy    | Behavior                                                                                            |",
+				"|-----------|-----------------------------------------------------------------------------------------------------|",
+				"| `omit`    | Null values are left out, both as the values of keys and as the elements of lists, sets and tuples. |",
+				"| `error`   | Null values result in an error naming their path.                                                   |",
+				"| `empty`   | Null values are written as the empty value of their type, e.g. `\"\"`, `0`, `false`, `[]` or `{}`.    |",
+				"| `comment` | Null values of keys are written as comments, e.g. `# key = null`, and are otherwise left out.       |",
+				"",
+				"With `empty`, an untyped `null` has no empty value and results in an error, so a typed null such as",
+				"`tostring(null)` must be used instead. With `comment`, null values within inline tables and arrays",
+				"are left out, as comments cannot be written there.",
 			},
 			"\n",
 		),
@@ -56,24 +112,58 @@ func (r EncodeFunction) Definition(_ context.Context, _ function.DefinitionReque
 			function.DynamicParameter{
 				Name:                "input",
 				MarkdownDescription: "Terraform value to encode",
+				AllowUnknownValues:  true,
 			},
 		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the layout of the result",
+			AllowUnknownValues:  true,
+		},
 		Return: function.StringReturn{},
 	}
 }
 
 func (r EncodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
 	var dynamicArg types.Dynamic
+	var optionsArgs []types.Dynamic
 
-	resp.Error = req.Arguments.Get(ctx, &dynamicArg)
+	resp.Error = req.Arguments.Get(ctx, &dynamicArg, &optionsArgs)
 
 	if resp.Error != nil {
 		return
 	}
 
-	objToEncode := convertFromTerraformType(dynamicArg)
+	// An encoding of a partially unknown value would be wrong, so the result is
+	// unknown until every nested value is known.
+	known := isFullyKnown(ctx, dynamicArg)
+	for _, optionsArg := range optionsArgs {
+		known = known && isFullyKnown(ctx, optionsArg)
+	}
+	if !known {
+		resp.Error = resp.Result.Set(ctx, types.StringUnknown())
+		return
+	}
 
-	encodedContent, err := toml.Marshal(objToEncode)
+	var options tomlEncoderOptions
+	options, resp.Error = parseEncodeOptionsArgument(optionsArgs)
+	if resp.Error != nil {
+		return
+	}
+
+	value, err := convertFromTerraformTypeForEncoding(dynamicArg, tomlConversion{
+		nullPolicy:     options.nullPolicy,
+		inexactNumbers: options.inexactNumbers,
+	})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The value cannot be converted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	encodedContent, err := encodeToml(value, options)
 	if err != nil {
 		resp.Error = function.NewArgumentFuncError(
 			0,
@@ -85,60 +175,254 @@ func (r EncodeFunction) Run(ctx context.Context, req function.RunRequest, resp *
 	resp.Error = resp.Result.Set(ctx, types.StringValue(string(encodedContent)))
 }
 
-func convertFromTerraformType(dynamicValue attr.Value) any {
+// parseEncodeOptionsArgument parses the variadic options argument of the
+// encode and encode_report functions.
+func parseEncodeOptionsArgument(optionsArgs []types.Dynamic) (tomlEncoderOptions, *function.FuncError) {
+	options := defaultTomlEncoderOptions()
+	if len(optionsArgs) > 1 {
+		return options, function.NewArgumentFuncError(
+			2,
+			"At most one options argument may be given",
+		)
+	}
+	if len(optionsArgs) == 1 {
+		optionsValue, err := convertFromTerraformType(optionsArgs[0].UnderlyingValue())
+		if err == nil {
+			options, err = parseTomlEncoderOptions(optionsValue)
+		}
+		if err != nil {
+			return options, function.NewArgumentFuncError(
+				1,
+				fmt.Sprintf("The encode options are invalid.\n\nOriginal Error: %s", err),
+			)
+		}
+	}
+	return options, nil
+}
+
+// isFullyKnown returns whether a Terraform value is known, including any
+// values nested within it.
+func isFullyKnown(ctx context.Context, value attr.Value) bool {
+	terraformValue, err := value.ToTerraformValue(ctx)
+	return err == nil && terraformValue.IsFullyKnown()
+}
+
+// encodeTerraformValue encodes a Terraform value as a TOML document.
+func encodeTerraformValue(value attr.Value) ([]byte, error) {
+	convertedValue, err := convertFromTerraformType(value)
+	if err != nil {
+		return nil, err
+	}
+	return encodeToml(convertedValue, defaultTomlEncoderOptions())
+}
+
+// convertFromTerraformType converts a Terraform value to a Go value which can
+// be encoded as TOML. Null values are converted to nil.
+func convertFromTerraformType(value attr.Value) (any, error) {
+	return convertValueFromTerraformType(nil, value, tomlConversion{})
+}
+
+// tomlConversion configures the conversion of a Terraform value for encoding.
+// The zero value converts null values to nil and reports nothing.
+type tomlConversion struct {
+	// nullPolicy and inexactNumbers are the encoder options of the same name.
+	// Null values are converted to the empty value of their type with the
+	// "empty" null policy.
+	nullPolicy     string
+	inexactNumbers string
+	// warnings collects the conversions which the encoded TOML cannot
+	// faithfully represent, if not nil.
+	warnings *[]tomlConversionWarning
+}
+
+// tomlConversionWarning describes a value which is changed or lost when
+// encoded as TOML.
+type tomlConversionWarning struct {
+	path    tomlPath
+	kind    string
+	message string
+}
+
+// convertFromTerraformTypeForEncoding converts a Terraform value like
+// convertFromTerraformType, applying the null policy and reporting warnings as
+// configured. A null value is always converted to nil at the root.
+func convertFromTerraformTypeForEncoding(value attr.Value, conversion tomlConversion) (any, error) {
+	if value == nil || value.IsNull() {
+		return nil, nil
+	}
+	return convertValueFromTerraformType(nil, value, conversion)
+}
+
+func (c tomlConversion) warn(path tomlPath, kind string, message string) {
+	if c.warnings != nil {
+		*c.warnings = append(*c.warnings, tomlConversionWarning{path: path, kind: kind, message: message})
+	}
+}
+
+// convertValueFromTerraformType converts the Terraform value at the given path.
+func convertValueFromTerraformType(path tomlPath, dynamicValue attr.Value, conversion tomlConversion) (any, error) {
+	if dynamicValue == nil {
+		return nil, nil
+	}
 	if dynamicValue.IsNull() {
-		return nil
+		return convertNullFromTerraformType(path, dynamicValue, conversion)
+	}
+	if dynamicValue.IsUnknown() {
+		return nil, fmt.Errorf("the value at %s is unknown", formatConversionPath(path))
 	}
 	switch value := dynamicValue.(type) {
 	case types.String:
-		return value.ValueString()
+		if tomlType, ok := datetimeTypeOfString(value.ValueString()); ok {
+			functionName := tomlType
+			if tomlType == tomlTypeOffsetDateTime {
+				functionName = "datetime"
+			}
+			conversion.warn(path, "datetime_string", fmt.Sprintf("The string is a valid TOML %s value, but is written as a string. Use the %s function to write it as a native TOML value.", tomlType, functionName))
+		}
+		return value.ValueString(), nil
 	case types.Int64:
-		return value.ValueInt64()
+		return value.ValueInt64(), nil
 	case types.Float64:
-		return value.ValueFloat64()
+		return value.ValueFloat64(), nil
 	case types.Number:
-		bigFloat := value.ValueBigFloat()
-		if bigFloat.IsInt() {
-			intValue, _ := bigFloat.Int64()
-			return intValue
+		number := convertNumberFromTerraformType(value.ValueBigFloat())
+		if _, inexact := number.(*big.Float); inexact {
+			switch conversion.inexactNumbers {
+			case "round":
+				conversion.warn(path, "inexact_number", "The number cannot be represented exactly by a TOML integer or float, and is rounded to the nearest float.")
+			case "string":
+				conversion.warn(path, "inexact_number", "The number cannot be represented exactly by a TOML integer or float, and is written as a string.")
+			}
 		}
-		floatValue, _ := bigFloat.Float64()
-		return floatValue
+		return number, nil
 	case types.Bool:
-		return value.ValueBool()
+		return value.ValueBool(), nil
 	case types.List:
-		return convertSliceFromTerraformType(value.Elements())
+		return convertSliceFromTerraformType(path, value.Elements(), conversion)
 	case types.Tuple:
-		return convertSliceFromTerraformType(value.Elements())
+		return convertSliceFromTerraformType(path, value.Elements(), conversion)
 	case types.Set:
-		return convertSliceFromTerraformType(value.Elements())
+		conversion.warn(path, "set", "The set is written as an array in the order Terraform sorts its elements, and is decoded as a tuple.")
+		return convertSliceFromTerraformType(path, value.Elements(), conversion)
 	case types.Map:
-		return convertMapFromTerraformType(value.Elements())
+		return convertMapFromTerraformType(path, value.Elements(), conversion)
 	case types.Object:
-		return convertMapFromTerraformType(value.Attributes())
+		return convertMapFromTerraformType(path, value.Attributes(), conversion)
 	case types.Dynamic:
-		return convertFromTerraformType(value.UnderlyingValue())
+		return convertValueFromTerraformType(path, value.UnderlyingValue(), conversion)
 	default:
-		panic(
-			fmt.Sprintf("Unable to convert value %v (type %T) from Terraform type", value, value),
-		)
+		return nil, fmt.Errorf("the value at %s has the unsupported type %s", formatConversionPath(path), value.Type(context.Background()))
+	}
+}
+
+// convertNullFromTerraformType converts a null Terraform value at the given
+// path according to the null policy.
+func convertNullFromTerraformType(path tomlPath, value attr.Value, conversion tomlConversion) (any, error) {
+	switch conversion.nullPolicy {
+	case "empty":
+		conversion.warn(path, "null", "The null value is written as the empty value of its type, as TOML has no null value.")
+		return emptyValueFromTerraformType(path, value)
+	case "comment":
+		conversion.warn(path, "null", "The null value is written as a comment, or omitted within inline tables and arrays, as TOML has no null value.")
+	case "omit":
+		conversion.warn(path, "null", "The null value is omitted, as TOML has no null value.")
+	}
+	return nil, nil
+}
+
+// emptyValueFromTerraformType returns the empty value of the type of a null
+// Terraform value: an empty string, zero, false, an empty array or an empty
+// table.
+func emptyValueFromTerraformType(path tomlPath, value attr.Value) (any, error) {
+	switch value := value.(type) {
+	case types.String:
+		return "", nil
+	case types.Int64, types.Number:
+		return int64(0), nil
+	case types.Float64:
+		return float64(0), nil
+	case types.Bool:
+		return false, nil
+	case types.List, types.Tuple, types.Set:
+		return []any{}, nil
+	case types.Map, types.Object:
+		return map[string]any{}, nil
+	case types.Dynamic:
+		if underlyingValue := value.UnderlyingValue(); underlyingValue != nil {
+			return emptyValueFromTerraformType(path, underlyingValue)
+		}
+		return nil, fmt.Errorf("the value at %s is null and has no type, so it has no empty value. Use a typed null such as tostring(null) instead", formatConversionPath(path))
+	default:
+		return nil, fmt.Errorf("the value at %s has the unsupported type %s", formatConversionPath(path), value.Type(context.Background()))
+	}
+}
+
+// formatConversionPath formats the path of a value being converted for use in errors.
+func formatConversionPath(path tomlPath) string {
+	if len(path) == 0 {
+		return "the root"
 	}
+	return path.String()
 }
 
-func convertMapFromTerraformType(elements map[string]attr.Value) map[string]any {
+// convertNumberFromTerraformType converts a number to an int64 or a float64,
+// or returns it as is if neither can represent it exactly.
+func convertNumberFromTerraformType(bigFloat *big.Float) any {
+	if bigFloat.IsInt() {
+		if intValue, accuracy := bigFloat.Int64(); accuracy == big.Exact {
+			return intValue
+		}
+		return bigFloat
+	}
+
+	floatValue, _ := bigFloat.Float64()
+	if math.IsInf(floatValue, 0) {
+		return bigFloat
+	}
+
+	// Numbers are parsed from decimal strings with a higher precision than a
+	// float64, so precision is only lost if the shortest decimal representation
+	// of the float64 is a different number. The comparison is made with a lower
+	// precision to ignore any rounding errors of calculations.
+	const comparisonPrecision = 100
+	shortest, _, err := big.ParseFloat(strconv.FormatFloat(floatValue, 'g', -1, 64), 10, bigFloat.Prec(), big.ToNearestEven)
+	if err != nil || new(big.Float).SetPrec(comparisonPrecision).Set(shortest).Cmp(new(big.Float).SetPrec(comparisonPrecision).Set(bigFloat)) != 0 {
+		return bigFloat
+	}
+	return floatValue
+}
+
+func convertMapFromTerraformType(path tomlPath, elements map[string]attr.Value, conversion tomlConversion) (map[string]any, error) {
+	warningCount := 0
+	if conversion.warnings != nil {
+		warningCount = len(*conversion.warnings)
+	}
+
 	result := make(map[string]any, len(elements))
 	for key, value := range elements {
-		convertedValue := convertFromTerraformType(value)
+		convertedValue, err := convertValueFromTerraformType(path.join(keyElement(key)), value, conversion)
+		if err != nil {
+			return nil, err
+		}
 		result[key] = convertedValue
 	}
-	return result
+
+	// Tagged values are encoded as native TOML values, so the conversion of
+	// their attributes is not lossy.
+	if _, tagged := asTaggedValue(result); tagged && conversion.warnings != nil {
+		*conversion.warnings = (*conversion.warnings)[:warningCount]
+	}
+	return result, nil
 }
 
-func convertSliceFromTerraformType(elements []attr.Value) []any {
+func convertSliceFromTerraformType(path tomlPath, elements []attr.Value, conversion tomlConversion) ([]any, error) {
 	result := make([]any, len(elements))
 	for i, value := range elements {
-		convertedValue := convertFromTerraformType(value)
+		convertedValue, err := convertValueFromTerraformType(path.join(indexElement(i)), value, conversion)
+		if err != nil {
+			return nil, err
+		}
 		result[i] = convertedValue
 	}
-	return result
+	return result, nil
 }
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = EncodeReportFunction{}
+)
+
+// tomlConversionWarningAttrTypes are the attribute types of the warnings
+// returned by the encode_report function.
+var tomlConversionWarningAttrTypes = map[string]attr.Type{
+	"path":    types.StringType,
+	"kind":    types.StringType,
+	"message": types.StringType,
+}
+
+// encodeReportResultAttrTypes are the attribute types of the object returned
+// by the encode_report function.
+var encodeReportResultAttrTypes = map[string]attr.Type{
+	"toml":     types.StringType,
+	"warnings": types.ListType{ElemType: types.ObjectType{AttrTypes: tomlConversionWarningAttrTypes}},
+}
+
+func NewEncodeReportFunction() function.Function {
+	return EncodeReportFunction{}
+}
+
+type EncodeReportFunction struct{}
+
+func (r EncodeReportFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "encode_report"
+}
+
+func (r EncodeReportFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Encode a value to TOML syntax, reporting any lossy conversions",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Encodes a Terraform value to TOML in the same way as the `encode` function, and reports every part",
+				"of the value which the result does not faithfully represent, so that a pipeline can fail on",
+				"unexpected changes. It accepts the same options as the `encode` function.",
+				"",
+				"The result has the following attributes:",
+				"",
+				"| Attribute  | Description                                                             |",
+				"|------------|-------------------------------------------------------------------------|",
+				"| `toml`     | The encoded TOML, as returned by the `encode` function.                 |",
+				"| `warnings` | A list of the lossy conversions made, which is empty if there are none. |",
+				"",
+				"Each warning is an object with a `path` attribute, in the same syntax as the `get` function,",
+				"a `message` attribute describing what happened, and one of the following `kind` attributes:",
+				"",
+				"| Kind              | Description                                                                              |",
+				"|-------------------|------------------------------------------------------------------------------------------|",
+				"| `set`             | A set is written as an array in the order Terraform sorts it, and is decoded as a tuple. |",
+				"| `null`            | A null value is omitted, written as a comment or written as an empty value.              |",
+				"| `datetime_string` | A string which is a valid TOML date or time value is written as a string.                |",
+				"| `inexact_number`  | A number which TOML cannot represent exactly is rounded or written as a string.          |",
+				"",
+				"Terraform does not distinguish whole floats from integers, so a whole number such as `1.0` is",
+				"always written as the integer `1` and is not reported. Use the `float` function to write it as a",
+				"float instead.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "input",
+				MarkdownDescription: "Terraform value to encode",
+				AllowUnknownValues:  true,
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the layout of the result",
+			AllowUnknownValues:  true,
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: encodeReportResultAttrTypes,
+		},
+	}
+}
+
+func (r EncodeReportFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var dynamicArg types.Dynamic
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &dynamicArg, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	known := isFullyKnown(ctx, dynamicArg)
+	for _, optionsArg := range optionsArgs {
+		known = known && isFullyKnown(ctx, optionsArg)
+	}
+	if !known {
+		resp.Error = resp.Result.Set(ctx, types.ObjectUnknown(encodeReportResultAttrTypes))
+		return
+	}
+
+	var options tomlEncoderOptions
+	options, resp.Error = parseEncodeOptionsArgument(optionsArgs)
+	if resp.Error != nil {
+		return
+	}
+
+	var warnings []tomlConversionWarning
+	value, err := convertFromTerraformTypeForEncoding(dynamicArg, tomlConversion{
+		nullPolicy:     options.nullPolicy,
+		inexactNumbers: options.inexactNumbers,
+		warnings:       &warnings,
+	})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The value cannot be converted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	encodedContent, err := encodeToml(value, options)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The value cannot be encoded to TOML.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	// Maps are converted in no particular order, so sort the warnings.
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if a, b := warnings[i].path.String(), warnings[j].path.String(); a != b {
+			return a < b
+		}
+		return warnings[i].kind < warnings[j].kind
+	})
+
+	warningValues := make([]attr.Value, 0, len(warnings))
+	for _, warning := range warnings {
+		warningValues = append(warningValues, types.ObjectValueMust(tomlConversionWarningAttrTypes, map[string]attr.Value{
+			"path":    types.StringValue(warning.path.String()),
+			"kind":    types.StringValue(warning.kind),
+			"message": types.StringValue(warning.message),
+		}))
+	}
+
+	warningsValue, diags := types.ListValue(types.ObjectType{AttrTypes: tomlConversionWarningAttrTypes}, warningValues)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	result, diags := types.ObjectValue(encodeReportResultAttrTypes, map[string]attr.Value{
+		"toml":     types.StringValue(string(encodedContent)),
+		"warnings": warningsValue,
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const testEncodeReportConfig = `
+output "test" {
+	value = provider::toml::encode_report({
+		name     = "example"
+		released = "2024-04-13"
+		built    = provider::toml::local_date("2024-04-14")
+		pi       = 3.14159265358979323846264
+		tags     = toset(["b", "a"])
+		bin      = [{ name = "first", path = null }]
+	})
+}
+
+output "clean" {
+	value = provider::toml::encode_report({ a = 1, b = ["c"] })
+}
+`
+
+const testEncodeReportInvalidConfig = `
+output "test" {
+	value = provider::toml::encode_report({ a = [1, null] }, { null_policy = "error" })
+}
+`
+
+func TestEncodeReportFunction(t *testing.T) {
+	warning := func(path string, kind string, message string) knownvalue.Check {
+		return knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"path":    knownvalue.StringExact(path),
+			"kind":    knownvalue.StringExact(kind),
+			"message": knownvalue.StringExact(message),
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testEncodeReportInvalidConfig,
+				ExpectError: regexp.MustCompile(`the\s+value\s+at\s+a\[1\]\s+is\s+null`),
+			},
+			{
+				Config: testEncodeReportConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"toml": knownvalue.StringExact("built = 2024-04-14\nname = 'example'\npi = 3.141592653589793\nreleased = '2024-04-13'\ntags = ['a', 'b']\n\n[[bin]]\nname = 'first'\n"),
+							"warnings": knownvalue.ListExact([]knownvalue.Check{
+								warning("bin[0].path", "null", "The null value is omitted, as TOML has no null value."),
+								warning("pi", "inexact_number", "The number cannot be represented exactly by a TOML integer or float, and is rounded to the nearest float."),
+								warning("released", "datetime_string", "The string is a valid TOML local_date value, but is written as a string. Use the local_date function to write it as a native TOML value."),
+								warning("tags", "set", "The set is written as an array in the order Terraform sorts its elements, and is decoded as a tuple."),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"clean",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"toml":     knownvalue.StringExact("a = 1\nb = ['c']\n"),
+							"warnings": knownvalue.ListExact([]knownvalue.Check{}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
@@ -1,13 +1,22 @@
 package provider
 
 import (
+	"context"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/statecheck"
 	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/pelletier/go-toml/v2"
 )
 
 const (
@@ -38,6 +47,179 @@ int = 1
 
 [[section.subsection]]
 string = 'value'
+`
+
+	testEncodeOptionsConfig = `
+output "test" {
+	value = provider::toml::encode(
+		{
+			"name": "example",
+			"section": {
+				"tags": ["a", "b"],
+				"subsection": [{"string": "value"}],
+			},
+		},
+		{
+			"table_indent": "  ",
+			"inline_tables": ["section.subsection"],
+			"multiline_arrays": true,
+			"quote_style": "basic",
+		},
+	)
+}
+`
+
+	testEncodeOptionsExpectedOutput = `name = "example"
+
+[section]
+  subsection = [
+    {string = "value"}
+  ]
+  tags = [
+    "a",
+    "b"
+  ]
+`
+
+	testEncodeKeyOrderConfig = `
+locals {
+	original = <<EOF
+[package]
+name = "example"
+version = "0.1.0"
+edition = "2021"
+
+[dependencies]
+serde = "1.0"
+anyhow = "1.0"
+EOF
+	decoded = provider::toml::decode(local.original)
+}
+
+output "test" {
+	value = provider::toml::encode(
+		merge(local.decoded, {
+			package = merge(local.decoded.package, { version = "0.2.0" })
+			dev-dependencies = { tempfile = "3" }
+		}),
+		{ key_order = local.original },
+	)
+}
+`
+
+	testEncodeKeyOrderExpectedOutput = `[package]
+name = 'example'
+version = '0.2.0'
+edition = '2021'
+
+[dependencies]
+serde = '1.0'
+anyhow = '1.0'
+
+[dev-dependencies]
+tempfile = '3'
+`
+
+	testEncodeNumbersConfig = `
+output "rounded" {
+	value = provider::toml::encode({ a = 3.14159265358979323846264, b = 1e400 }, { inexact_numbers = "round" })
+}
+
+output "default" {
+	value = provider::toml::encode({ x = 1/3 })
+}
+
+output "string" {
+	value = provider::toml::encode({ a = 3.14159265358979323846264, b = 9223372036854775808 }, { inexact_numbers = "string" })
+}
+
+output "special" {
+	value = provider::toml::encode(provider::toml::decode("a = inf\nb = -inf\nc = nan\n"))
+}
+
+output "decoded" {
+	value = provider::toml::decode("a = inf\nb = [-inf, nan]\n")
+}
+`
+
+	testEncodeLargeIntegerConfig = `
+output "test" {
+	value = provider::toml::encode({ a = 9223372036854775808 }, { inexact_numbers = "error" })
+}
+`
+
+	testEncodeUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "nested" {
+	value = provider::toml::encode({ a = { b = terraform_data.computed.output }, c = 1 })
+}
+
+output "options" {
+	value = provider::toml::encode({ a = 1 }, { table_indent = terraform_data.computed.output })
+}
+
+output "set" {
+	value = provider::toml::set("a = 1\n", "b", [terraform_data.computed.output])
+}
+`
+
+	testEncodeNullPolicyConfig = `
+variable "typed" {
+	type = object({
+		string = string
+		number = number
+		bool   = bool
+		list   = list(string)
+		map    = map(string)
+		object = object({ a = string })
+	})
+	default = {
+		string = null
+		number = null
+		bool   = null
+		list   = null
+		map    = null
+		object = null
+	}
+}
+
+output "omit" {
+	value = provider::toml::encode({
+		a     = null
+		tuple = ["x", null, 1]
+		list  = tolist(["y", null])
+		set   = toset(["z", null])
+	})
+}
+
+output "comment" {
+	value = provider::toml::encode({ a = null, b = { c = null, d = 1 } }, { null_policy = "comment" })
+}
+
+output "empty" {
+	value = provider::toml::encode(merge(var.typed, { tuple = [tostring(null), 1] }), { null_policy = "empty" })
+}
+`
+
+	testEncodeNullErrorConfig = `
+output "test" {
+	value = provider::toml::encode({ a = [1, null] }, { null_policy = "error" })
+}
+`
+
+	testEncodeUntypedNullConfig = `
+output "test" {
+	value = provider::toml::encode({ a = null }, { null_policy = "empty" })
+}
+`
+
+	testEncodeInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::encode({"name": "example"}, {"quote_style": "double"})
+}
 `
 )
 
@@ -60,3 +242,376 @@ func TestEncodeFunction(t *testing.T) {
 		},
 	})
 }
+
+func TestEncodeFunction_keyOrder(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testEncodeKeyOrderConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.StringExact(testEncodeKeyOrderExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestEncodeFunction_numbers(t *testing.T) {
+	specialFloat := func(value string) knownvalue.Check {
+		return knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"__toml_type": knownvalue.StringExact("float"),
+			"value":       knownvalue.StringExact(value),
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testEncodeLargeIntegerConfig,
+				ExpectError: regexp.MustCompile(`the\s+number\s+9223372036854775808\s+at\s+a\s+cannot\s+be\s+represented\s+as\s+an\s+integer`),
+			},
+			{
+				Config: testEncodeNumbersConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"rounded",
+						knownvalue.StringExact("a = 3.141592653589793\nb = inf\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"default",
+						knownvalue.StringExact("x = 0.3333333333333333\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"string",
+						knownvalue.StringExact("a = '3.14159265358979323846264'\nb = '9223372036854775808'\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"special",
+						knownvalue.StringExact("a = inf\nb = -inf\nc = nan\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"decoded",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"a": specialFloat("inf"),
+							"b": knownvalue.ListExact([]knownvalue.Check{
+								specialFloat("-inf"),
+								specialFloat("nan"),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestEncodeFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testEncodeUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("nested"),
+						plancheck.ExpectUnknownOutputValue("options"),
+						plancheck.ExpectUnknownOutputValue("set"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"nested",
+						knownvalue.StringExact("c = 1\n\n[a]\nb = 'value'\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"set",
+						knownvalue.StringExact("a = 1\nb = ['value']\n"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestEncodeFunction_options(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testEncodeInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`quote_style\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config: testEncodeOptionsConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.StringExact(testEncodeOptionsExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestEncodeFunction_nullPolicy(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testEncodeNullErrorConfig,
+				ExpectError: regexp.MustCompile(`the\s+value\s+at\s+a\[1\]\s+is\s+null`),
+			},
+			{
+				Config:      testEncodeUntypedNullConfig,
+				ExpectError: regexp.MustCompile(`the\s+value\s+at\s+a\s+is\s+null\s+and\s+has\s+no\s+type`),
+			},
+			{
+				Config: testEncodeNullPolicyConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"omit",
+						knownvalue.StringExact("list = ['y']\nset = ['z']\ntuple = ['x', 1]\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"comment",
+						knownvalue.StringExact("# a = null\n\n[b]\n# c = null\nd = 1\n"),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"empty",
+						knownvalue.StringExact("bool = false\nlist = []\nnumber = 0\nstring = ''\ntuple = ['', 1]\n\n[map]\n\n[object]\n"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestConvertNumberFromTerraformType(t *testing.T) {
+	// Terraform parses numbers with 512 bits of precision.
+	parse := func(s string) *big.Float {
+		value, _, err := big.ParseFloat(s, 10, 512, big.ToNearestEven)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	testCases := map[string]struct {
+		value    *big.Float
+		expected any
+	}{
+		"integer":              {value: parse("42"), expected: int64(42)},
+		"largest integer":      {value: parse("9223372036854775807"), expected: int64(9223372036854775807)},
+		"smallest integer":     {value: parse("-9223372036854775808"), expected: int64(-9223372036854775808)},
+		"integer out of range": {value: parse("9223372036854775808"), expected: nil},
+		"float":                {value: parse("0.1"), expected: 0.1},
+		"negative float":       {value: parse("-2.5e-3"), expected: -2.5e-3},
+		"calculated float":     {value: new(big.Float).SetPrec(512).Add(parse("0.1"), parse("0.2")), expected: 0.3},
+		"too precise":          {value: parse("3.14159265358979323846264"), expected: nil},
+		"float out of range":   {value: parse("1.5e400"), expected: nil},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := convertNumberFromTerraformType(testCase.value)
+			if testCase.expected == nil {
+				if _, ok := actual.(*big.Float); !ok {
+					t.Fatalf("expected the number to be returned as is, got %T %v", actual, actual)
+				}
+				return
+			}
+			if actual != testCase.expected {
+				t.Errorf("unexpected result %T %v, expected %T %v", actual, actual, testCase.expected, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestConvertFromTerraformType_errors(t *testing.T) {
+	nested := func(value attr.Value) attr.Value {
+		return types.ObjectValueMust(
+			map[string]attr.Type{"a": types.TupleType{ElemTypes: []attr.Type{types.StringType, value.Type(context.Background())}}},
+			map[string]attr.Value{"a": types.TupleValueMust(
+				[]attr.Type{types.StringType, value.Type(context.Background())},
+				[]attr.Value{types.StringValue("b"), value},
+			)},
+		)
+	}
+
+	testCases := map[string]struct {
+		value    attr.Value
+		expected string
+	}{
+		"unknown root": {
+			value:    types.DynamicUnknown(),
+			expected: "the value at the root is unknown",
+		},
+		"unknown nested value": {
+			value:    nested(types.StringUnknown()),
+			expected: "the value at a[1] is unknown",
+		},
+		"unknown dynamic value": {
+			value:    nested(types.DynamicValue(types.Int64Unknown())),
+			expected: "the value at a[1] is unknown",
+		},
+		"unsupported type": {
+			value:    nested(types.Int32Value(1)),
+			expected: "the value at a[1] has the unsupported type basetypes.Int32Type",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := convertFromTerraformType(testCase.value)
+			if err == nil {
+				t.Fatalf("expected error, got %v", actual)
+			}
+			if err.Error() != testCase.expected {
+				t.Errorf("unexpected error %q, expected %q", err, testCase.expected)
+			}
+		})
+	}
+}
+
+// FuzzEncodeTerraformValue checks that converting and encoding arbitrary
+// Terraform values returns an error rather than panicking, and that encoded
+// tables can be decoded.
+func FuzzEncodeTerraformValue(f *testing.F) {
+	f.Add([]byte{9, 2, 1, 'a', 2, 3, 'x', 'y', 'z', 1, 'b', 1})
+	f.Add([]byte{10, 3, 1, 'a', 7, 2, 3, 42, 5, 1, 1, 'b', 11, 4, 0, 0, 0, 0, 0, 0, 0xf0, 0x7f})
+	f.Add([]byte{9, 1, 1, 'c', 8, 2, 9, 1, 1, 'x', 6, 1, 12})
+	f.Add([]byte{9, 1, 0, 5, 0xff, 0x80})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		value := newFuzzTerraformValue(&data, 0)
+		converted, err := convertFromTerraformType(value)
+		if err != nil {
+			return
+		}
+		content, err := encodeToml(converted, defaultTomlEncoderOptions())
+		if _, ok := converted.(map[string]any); err != nil || !ok {
+			// Values other than tables are encoded as is, like toml.Marshal does.
+			return
+		}
+		var decoded any
+		if err := toml.Unmarshal(content, &decoded); err != nil {
+			t.Fatalf("the encoded document cannot be decoded: %s\n%s", err, content)
+		}
+	})
+}
+
+// newFuzzTerraformValue builds a Terraform value from fuzzing input.
+func newFuzzTerraformValue(data *[]byte, depth int) attr.Value {
+	next := func() byte {
+		if len(*data) == 0 {
+			return 0
+		}
+		b := (*data)[0]
+		*data = (*data)[1:]
+		return b
+	}
+	nextString := func() string {
+		n := min(int(next()%16), len(*data))
+		// Terraform strings are always valid UTF-8.
+		s := strings.ToValidUTF8(string((*data)[:n]), "?")
+		*data = (*data)[n:]
+		return s
+	}
+	nextUint64 := func() uint64 {
+		var n uint64
+		for i := 0; i < 8; i++ {
+			n |= uint64(next()) << (8 * i)
+		}
+		return n
+	}
+	nextFloat64 := func() float64 {
+		// Terraform numbers cannot be NaN.
+		f := math.Float64frombits(nextUint64())
+		if math.IsNaN(f) {
+			return 0
+		}
+		return f
+	}
+	elements := func() []attr.Value {
+		var elements []attr.Value
+		for n := int(next() % 4); n > 0; n-- {
+			elements = append(elements, types.DynamicValue(newFuzzTerraformValue(data, depth+1)))
+		}
+		return elements
+	}
+	attributes := func() map[string]attr.Value {
+		attributes := map[string]attr.Value{}
+		for n := int(next() % 4); n > 0; n-- {
+			attributes[nextString()] = types.DynamicValue(newFuzzTerraformValue(data, depth+1))
+		}
+		return attributes
+	}
+	elementTypes := func(n int) []attr.Type {
+		elementTypes := make([]attr.Type, n)
+		for i := range elementTypes {
+			elementTypes[i] = types.DynamicType
+		}
+		return elementTypes
+	}
+
+	kind := next() % 13
+	if depth >= 4 && kind >= 7 && kind <= 10 {
+		kind = 2
+	}
+	switch kind {
+	case 0:
+		return types.StringNull()
+	case 1:
+		return types.StringUnknown()
+	case 2:
+		return types.StringValue(nextString())
+	case 3:
+		return types.Int64Value(int64(nextUint64()))
+	case 4:
+		return types.Float64Value(nextFloat64())
+	case 5:
+		mantissa := big.NewFloat(float64(int8(next())))
+		return types.NumberValue(mantissa.SetMantExp(mantissa, int(int8(next()))))
+	case 6:
+		return types.BoolValue(next()%2 == 0)
+	case 7:
+		tuple := elements()
+		return types.TupleValueMust(elementTypes(len(tuple)), tuple)
+	case 8:
+		return types.ListValueMust(types.DynamicType, elements())
+	case 9:
+		object := attributes()
+		attributeTypes := make(map[string]attr.Type, len(object))
+		for key := range object {
+			attributeTypes[key] = types.DynamicType
+		}
+		return types.ObjectValueMust(attributeTypes, object)
+	case 10:
+		return types.MapValueMust(types.DynamicType, attributes())
+	case 11:
+		return types.DynamicValue(types.Float64Value(nextFloat64()))
+	default:
+		return types.DynamicUnknown()
+	}
+}
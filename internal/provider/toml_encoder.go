@@ -0,0 +1,695 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// tomlEncoderOptions control the layout of encoded TOML documents. The default
+// options produce the same output as toml.Marshal.
+type tomlEncoderOptions struct {
+	// tableIndent is added to the indentation for each level of table nesting.
+	tableIndent string
+	// arrayIndent is added to the indentation of the elements of multiline arrays.
+	arrayIndent string
+	// inlineTables are the paths of tables (and arrays of tables) to encode inline.
+	inlineTables []tomlPath
+	// inlineDepth is the depth below which tables are encoded inline, or -1
+	// for no limit.
+	inlineDepth     int
+	multilineArrays bool
+	// basicStrings forces strings and quoted keys to use basic (double-quoted)
+	// strings, rather than literal strings where possible.
+	basicStrings    bool
+	trailingNewline bool
+	// keyOrder is the order in which to write keys, or nil to sort them.
+	keyOrder tomlKeyOrder
+	// inexactNumbers is how to encode numbers which cannot be represented
+	// exactly by a TOML integer or float: "error", "round" or "string".
+	inexactNumbers string
+	// nullPolicy is how to encode null values: "omit", "error", "empty" or
+	// "comment". Null values have already been replaced by empty values by
+	// convertFromTerraformTypeForEncoding for "empty".
+	nullPolicy string
+}
+
+func defaultTomlEncoderOptions() tomlEncoderOptions {
+	return tomlEncoderOptions{
+		arrayIndent:     "  ",
+		inlineDepth:     -1,
+		trailingNewline: true,
+		inexactNumbers:  "round",
+		nullPolicy:      "omit",
+	}
+}
+
+// parseTomlEncoderOptions parses the options argument of the encode function,
+// as returned by convertFromTerraformType.
+func parseTomlEncoderOptions(options any) (tomlEncoderOptions, error) {
+	result := defaultTomlEncoderOptions()
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return result, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "table_indent":
+			result.tableIndent, ok = value.(string)
+		case "array_indent":
+			result.arrayIndent, ok = value.(string)
+		case "inline_tables":
+			var paths []any
+			paths, ok = value.([]any)
+			for _, path := range paths {
+				pathString, isString := path.(string)
+				if !isString {
+					ok = false
+					break
+				}
+				inlinePath, err := parseTomlPath(pathString)
+				if err != nil {
+					return result, fmt.Errorf("invalid path in inline_tables: %w", err)
+				}
+				result.inlineTables = append(result.inlineTables, inlinePath.keys())
+			}
+		case "inline_depth":
+			var depth int64
+			depth, ok = value.(int64)
+			if ok && depth < 0 {
+				return result, fmt.Errorf("inline_depth must not be negative, got: %d", depth)
+			}
+			result.inlineDepth = int(depth)
+		case "multiline_arrays":
+			result.multilineArrays, ok = value.(bool)
+		case "quote_style":
+			var quoteStyle string
+			quoteStyle, ok = value.(string)
+			if ok && quoteStyle != "literal" && quoteStyle != "basic" {
+				return result, fmt.Errorf("quote_style must be one of \"literal\" or \"basic\", got: %q", quoteStyle)
+			}
+			result.basicStrings = quoteStyle == "basic"
+		case "trailing_newline":
+			result.trailingNewline, ok = value.(bool)
+		case "inexact_numbers":
+			result.inexactNumbers, ok = value.(string)
+			if ok && result.inexactNumbers != "error" && result.inexactNumbers != "round" && result.inexactNumbers != "string" {
+				return result, fmt.Errorf("inexact_numbers must be one of \"error\", \"round\" or \"string\", got: %q", result.inexactNumbers)
+			}
+		case "null_policy":
+			result.nullPolicy, ok = value.(string)
+			if ok && result.nullPolicy != "omit" && result.nullPolicy != "error" && result.nullPolicy != "empty" && result.nullPolicy != "comment" {
+				return result, fmt.Errorf("null_policy must be one of \"omit\", \"error\", \"empty\" or \"comment\", got: %q", result.nullPolicy)
+			}
+		case "key_order":
+			var content string
+			content, ok = value.(string)
+			if ok {
+				keyOrder, err := newTomlKeyOrder([]byte(content))
+				if err != nil {
+					return result, fmt.Errorf("key_order cannot be decoded: %w", err)
+				}
+				result.keyOrder = keyOrder
+			}
+		default:
+			return result, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return result, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+	return result, nil
+}
+
+// tomlKeyOrder is the order in which keys first appear in each table of a TOML
+// document. Tables are identified by their path without array indexes, so the
+// entries of an array of tables share the same order.
+type tomlKeyOrder map[string]map[string]int
+
+// newTomlKeyOrder determines the order of the keys of a TOML document.
+func newTomlKeyOrder(data []byte) (tomlKeyOrder, error) {
+	doc, err := parseTomlDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make(tomlKeyOrder)
+	for _, section := range doc.sections {
+		sectionPath := section.path.keys()
+		for i := range sectionPath {
+			order.add(sectionPath[:i], sectionPath[i].key)
+		}
+		for _, keyValue := range section.keyValues {
+			order.addValue(sectionPath, keyValue.key, keyValue.value)
+		}
+	}
+	return order, nil
+}
+
+// add records a key of the table at the given path, if not already present.
+func (o tomlKeyOrder) add(path tomlPath, key string) {
+	table := path.String()
+	if o[table] == nil {
+		o[table] = make(map[string]int)
+	}
+	if _, ok := o[table][key]; !ok {
+		o[table][key] = len(o[table])
+	}
+}
+
+// addValue records the keys of a possibly dotted key-value, and of any inline
+// tables within its value.
+func (o tomlKeyOrder) addValue(path tomlPath, key tomlPath, value *tomlValueNode) {
+	for _, element := range key {
+		o.add(path, element.key)
+		path = path.join(element)
+	}
+	for _, entry := range value.entries {
+		o.addValue(path, entry.key, entry.value)
+	}
+}
+
+// sort sorts the keys of the table at the given path in the order they were
+// recorded. Any other keys follow them in lexical order.
+func (o tomlKeyOrder) sort(path tomlPath, keys []string) {
+	order := o[path.String()]
+	sort.Slice(keys, func(i, j int) bool {
+		iIndex, iOK := order[keys[i]]
+		jIndex, jOK := order[keys[j]]
+		switch {
+		case iOK && jOK:
+			return iIndex < jIndex
+		case iOK != jOK:
+			return iOK
+		default:
+			return keys[i] < keys[j]
+		}
+	})
+}
+
+// tomlEncoder encodes values returned by convertFromTerraformType as TOML.
+type tomlEncoder struct {
+	options tomlEncoderOptions
+	b       []byte
+}
+
+// encodeToml encodes a value as a TOML document using the given options.
+func encodeToml(value any, options tomlEncoderOptions) ([]byte, error) {
+	e := &tomlEncoder{options: options}
+
+	value, err := removeTomlNulls(nil, value, options.nullPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if table, ok := value.(map[string]any); ok {
+		err = e.encodeTable(nil, table, false)
+	} else if value == nil {
+		err = fmt.Errorf("toml: cannot encode a nil interface")
+	} else {
+		err = e.encodeValue(nil, value, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.trailingNewline {
+		e.b = []byte(strings.TrimSuffix(string(e.b), "\n"))
+	} else if len(e.b) > 0 && e.b[len(e.b)-1] != '\n' {
+		e.b = append(e.b, '\n')
+	}
+	return e.b, nil
+}
+
+// removeTomlNulls applies a null policy to the values nested within a value.
+// Null elements of arrays are removed, as TOML has no null value, and null
+// values of tables are kept so that the encoder can skip them or write them
+// as comments. With the "error" policy, an error naming the path of the first
+// null value is returned instead.
+func removeTomlNulls(path tomlPath, value any, nullPolicy string) (any, error) {
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		result := make(map[string]any, len(value))
+		for _, key := range keys {
+			element, err := removeTomlNulls(path.join(keyElement(key)), value[key], nullPolicy)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = element
+		}
+		return result, nil
+	case []any:
+		result := make([]any, 0, len(value))
+		for i, element := range value {
+			element, err := removeTomlNulls(path.join(indexElement(i)), element, nullPolicy)
+			if err != nil {
+				return nil, err
+			}
+			if element != nil {
+				result = append(result, element)
+			}
+		}
+		return result, nil
+	case nil:
+		if nullPolicy == "error" && len(path) > 0 {
+			return nil, fmt.Errorf("the value at %s is null. Set the null_policy option to \"omit\", \"empty\" or \"comment\" to encode it anyway", path)
+		}
+	}
+	return value, nil
+}
+
+// isInline returns whether the table (or array of tables) at the given path
+// should be encoded inline.
+func (e *tomlEncoder) isInline(path tomlPath) bool {
+	if e.options.inlineDepth >= 0 && len(path) > e.options.inlineDepth {
+		return true
+	}
+	for _, inlinePath := range e.options.inlineTables {
+		if inlinePath.equal(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTable returns whether the value at the given path should be encoded as a
+// table, or as an array of tables.
+func (e *tomlEncoder) isTable(path tomlPath, value any) bool {
+	if e.isInline(path) {
+		return false
+	}
+	if _, ok := asTomlTable(value); ok {
+		return true
+	}
+	_, ok := asArrayOfTables(value)
+	return ok
+}
+
+// encodeTable encodes a table using a header, followed by its key-values and
+// then its sub-tables. The header is skipped for the root table and for
+// entries of arrays of tables.
+func (e *tomlEncoder) encodeTable(path tomlPath, table map[string]any, skipHeader bool) error {
+	if len(path) > 0 && !skipHeader {
+		e.indent(len(path) - 1)
+		e.b = append(e.b, '[')
+		e.encodePathKeys(path)
+		e.b = append(e.b, "]\n"...)
+	}
+
+	keys := e.sortedKeys(path, table, e.options.nullPolicy == "comment")
+
+	var tableKeys []string
+	for _, key := range keys {
+		value := table[key]
+		if value == nil {
+			e.indent(len(path))
+			e.b = append(e.b, "# "...)
+			e.encodeKey(key)
+			e.b = append(e.b, " = null\n"...)
+			continue
+		}
+		if e.isTable(path.join(keyElement(key)), value) {
+			tableKeys = append(tableKeys, key)
+			continue
+		}
+
+		e.indent(len(path))
+		e.encodeKey(key)
+		e.b = append(e.b, " = "...)
+		if err := e.encodeValue(path.join(keyElement(key)), value, len(path)); err != nil {
+			return err
+		}
+		e.b = append(e.b, '\n')
+	}
+
+	for i, key := range tableKeys {
+		if i > 0 || len(tableKeys) < len(keys) {
+			e.b = append(e.b, '\n')
+		}
+
+		subPath := path.join(keyElement(key))
+		var err error
+		switch value := table[key].(type) {
+		case map[string]any:
+			err = e.encodeTable(subPath, value, false)
+		case []any:
+			err = e.encodeArrayOfTables(subPath, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeArrayOfTables encodes an array of tables using a header for each entry.
+func (e *tomlEncoder) encodeArrayOfTables(path tomlPath, entries []any) error {
+	for i, entry := range entries {
+		if i > 0 {
+			e.b = append(e.b, '\n')
+		}
+		e.indent(len(path) - 1)
+		e.b = append(e.b, "[["...)
+		e.encodePathKeys(path)
+		e.b = append(e.b, "]]\n"...)
+
+		if err := e.encodeTable(path, entry.(map[string]any), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a table in the order they should be written,
+// skipping any null values unless includeNulls is set.
+func (e *tomlEncoder) sortedKeys(path tomlPath, table map[string]any, includeNulls bool) []string {
+	keys := make([]string, 0, len(table))
+	for key, value := range table {
+		if value != nil || includeNulls {
+			keys = append(keys, key)
+		}
+	}
+	if e.options.keyOrder != nil {
+		e.options.keyOrder.sort(path, keys)
+	} else {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// encodeValue encodes a value of a key-value at the given path, or an element
+// of an array. The level is the number of table indentations of the key-value.
+func (e *tomlEncoder) encodeValue(path tomlPath, value any, level int) error {
+	return e.encodeInlineValue(path, value, level, "")
+}
+
+func (e *tomlEncoder) encodeInlineValue(path tomlPath, value any, level int, arrayIndentation string) error {
+	switch value := value.(type) {
+	case nil:
+		return fmt.Errorf("toml: encoding a nil interface is not supported")
+	case string:
+		e.encodeString(value)
+	case bool:
+		e.b = strconv.AppendBool(e.b, value)
+	case int64:
+		e.b = strconv.AppendInt(e.b, value, 10)
+	case float64:
+		e.encodeFloat(value)
+	case *big.Float:
+		return e.encodeInexactNumber(path, value)
+	case map[string]any:
+		if tagged, ok := asTaggedValue(value); ok {
+			return e.encodeTaggedValue(tagged)
+		}
+
+		keys := e.sortedKeys(path, value, false)
+
+		e.b = append(e.b, '{')
+		for i, key := range keys {
+			if i > 0 {
+				e.b = append(e.b, ", "...)
+			}
+			e.encodeKey(key)
+			e.b = append(e.b, " = "...)
+			if err := e.encodeInlineValue(path.join(keyElement(key)), value[key], level, arrayIndentation); err != nil {
+				return err
+			}
+		}
+		e.b = append(e.b, '}')
+	case []any:
+		if len(value) == 0 {
+			e.b = append(e.b, "[]"...)
+			return nil
+		}
+
+		e.b = append(e.b, '[')
+		elementIndentation := arrayIndentation + e.options.arrayIndent
+		for i, element := range value {
+			if i > 0 {
+				e.b = append(e.b, ',')
+				if !e.options.multilineArrays {
+					e.b = append(e.b, ' ')
+				}
+			}
+			if e.options.multilineArrays {
+				e.b = append(e.b, '\n')
+				e.indent(level)
+				e.b = append(e.b, elementIndentation...)
+			}
+			if err := e.encodeInlineValue(path, element, level, elementIndentation); err != nil {
+				return err
+			}
+		}
+		if e.options.multilineArrays {
+			e.b = append(e.b, '\n')
+			e.indent(level)
+			e.b = append(e.b, arrayIndentation...)
+		}
+		e.b = append(e.b, ']')
+	default:
+		return fmt.Errorf("toml: cannot encode value of type %T", value)
+	}
+	return nil
+}
+
+// encodeTaggedValue encodes a value of an explicit TOML type.
+func (e *tomlEncoder) encodeTaggedValue(tagged tomlTaggedValue) error {
+	switch tagged.tomlType {
+	case tomlTypeFloat:
+		switch value := tagged.value.(type) {
+		case int64:
+			e.encodeFloat(float64(value))
+		case float64:
+			e.encodeFloat(value)
+		case *big.Float:
+			// The value is explicitly a float, so it is rounded as necessary.
+			floatValue, _ := value.Float64()
+			e.encodeFloat(floatValue)
+		case string:
+			floatValue, err := parseSpecialFloat(value)
+			if err != nil {
+				return err
+			}
+			e.encodeFloat(floatValue)
+		default:
+			return fmt.Errorf("the value of a float must be a number, got: %T", tagged.value)
+		}
+	case tomlTypeInteger:
+		value, ok := tagged.value.(int64)
+		if !ok {
+			return fmt.Errorf("the value of an integer must be a whole number between %d and %d, got: %v", math.MinInt64, math.MaxInt64, tagged.value)
+		}
+		text, err := formatTaggedInteger(value, tagged.format)
+		if err != nil {
+			return err
+		}
+		e.b = append(e.b, text...)
+	case tomlTypeString:
+		value, ok := tagged.value.(string)
+		if !ok {
+			return fmt.Errorf("the value of a string must be a string, got: %T", tagged.value)
+		}
+		switch tagged.format {
+		case "":
+			e.encodeString(value)
+		case "multiline":
+			e.encodeMultilineString(value)
+		default:
+			return fmt.Errorf("unsupported string format %q, expected \"multiline\"", tagged.format)
+		}
+	default:
+		value, ok := tagged.value.(string)
+		if !ok {
+			return fmt.Errorf("the value of a %s must be a string, got: %T", tagged.tomlType, tagged.value)
+		}
+		if err := checkTaggedDatetime(tagged.tomlType, value); err != nil {
+			return err
+		}
+		e.b = append(e.b, value...)
+	}
+	return nil
+}
+
+// encodeInexactNumber encodes a number which cannot be represented exactly by
+// a TOML integer or float, according to the inexact_numbers option.
+func (e *tomlEncoder) encodeInexactNumber(path tomlPath, value *big.Float) error {
+	text := value.Text('f', -1)
+	if value.IsInt() {
+		intValue, _ := value.Int(nil)
+		text = intValue.String()
+	}
+
+	switch e.options.inexactNumbers {
+	case "round":
+		floatValue, _ := value.Float64()
+		e.encodeFloat(floatValue)
+	case "string":
+		e.encodeString(text)
+	default:
+		location := "the value"
+		if len(path) > 0 {
+			location = path.String()
+		}
+		kind := "a float without losing precision"
+		if value.IsInt() {
+			kind = fmt.Sprintf("an integer, which must be between %d and %d", math.MinInt64, math.MaxInt64)
+		}
+		return fmt.Errorf("the number %s at %s cannot be represented as %s. Set the inexact_numbers option to \"round\" or \"string\" to encode it anyway", text, location, kind)
+	}
+	return nil
+}
+
+func (e *tomlEncoder) encodeFloat(value float64) {
+	switch {
+	case math.IsNaN(value):
+		e.b = append(e.b, "nan"...)
+	case math.IsInf(value, 1):
+		e.b = append(e.b, "inf"...)
+	case math.IsInf(value, -1):
+		e.b = append(e.b, "-inf"...)
+	case math.Trunc(value) == value:
+		e.b = strconv.AppendFloat(e.b, value, 'f', 1, 64)
+	default:
+		e.b = strconv.AppendFloat(e.b, value, 'f', -1, 64)
+	}
+}
+
+// encodeString encodes a string, as a literal string where possible unless
+// basic strings have been requested.
+func (e *tomlEncoder) encodeString(value string) {
+	if e.options.basicStrings || !canBeLiteralString(value) {
+		e.encodeBasicString(value)
+		return
+	}
+	e.b = append(e.b, '\'')
+	e.b = append(e.b, value...)
+	e.b = append(e.b, '\'')
+}
+
+func (e *tomlEncoder) encodeBasicString(value string) {
+	const hex = "0123456789ABCDEF"
+
+	e.b = append(e.b, '"')
+	for _, c := range []byte(value) {
+		switch c {
+		case '\\':
+			e.b = append(e.b, `\\`...)
+		case '"':
+			e.b = append(e.b, `\"`...)
+		case '\b':
+			e.b = append(e.b, `\b`...)
+		case '\f':
+			e.b = append(e.b, `\f`...)
+		case '\n':
+			e.b = append(e.b, `\n`...)
+		case '\r':
+			e.b = append(e.b, `\r`...)
+		case '\t':
+			e.b = append(e.b, `\t`...)
+		default:
+			if c < 0x20 || c == 0x7f {
+				e.b = append(e.b, `\u00`...)
+				e.b = append(e.b, hex[c>>4], hex[c&0x0f])
+			} else {
+				e.b = append(e.b, c)
+			}
+		}
+	}
+	e.b = append(e.b, '"')
+}
+
+// encodeMultilineString encodes a multiline string, as a multiline literal
+// string where possible unless basic strings have been requested. The string
+// starts on the line after the opening delimiter.
+func (e *tomlEncoder) encodeMultilineString(value string) {
+	if !e.options.basicStrings && canBeMultilineLiteralString(value) {
+		e.b = append(e.b, "'''\n"...)
+		e.b = append(e.b, value...)
+		e.b = append(e.b, "'''"...)
+		return
+	}
+
+	const hex = "0123456789ABCDEF"
+
+	e.b = append(e.b, `"""`+"\n"...)
+	for _, c := range []byte(value) {
+		switch c {
+		case '\\':
+			e.b = append(e.b, `\\`...)
+		case '"':
+			e.b = append(e.b, `\"`...)
+		case '\n', '\t':
+			e.b = append(e.b, c)
+		case '\r':
+			e.b = append(e.b, `\r`...)
+		default:
+			if c < 0x20 || c == 0x7f {
+				e.b = append(e.b, `\u00`...)
+				e.b = append(e.b, hex[c>>4], hex[c&0x0f])
+			} else {
+				e.b = append(e.b, c)
+			}
+		}
+	}
+	e.b = append(e.b, `"""`...)
+}
+
+// canBeLiteralString returns whether a string can be written as a literal string.
+func canBeLiteralString(value string) bool {
+	for _, c := range []byte(value) {
+		if c == '\'' || c == '\n' || c == '\r' || c < 0x20 && c != '\t' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeKey encodes a key, quoting it if necessary.
+func (e *tomlEncoder) encodeKey(key string) {
+	if key == "" {
+		if e.options.basicStrings {
+			e.b = append(e.b, `""`...)
+		} else {
+			e.b = append(e.b, "''"...)
+		}
+		return
+	}
+
+	for _, c := range []byte(key) {
+		if !isBareKeyChar(c) {
+			e.encodeString(key)
+			return
+		}
+	}
+	e.b = append(e.b, key...)
+}
+
+func (e *tomlEncoder) encodePathKeys(path tomlPath) {
+	for i, element := range path {
+		if i > 0 {
+			e.b = append(e.b, '.')
+		}
+		e.encodeKey(element.key)
+	}
+}
+
+func (e *tomlEncoder) indent(level int) {
+	for i := 0; i < level; i++ {
+		e.b = append(e.b, e.options.tableIndent...)
+	}
+}
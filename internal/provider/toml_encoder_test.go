@@ -0,0 +1,463 @@
+package provider
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestEncodeToml_defaults(t *testing.T) {
+	testCases := map[string]any{
+		"empty": map[string]any{},
+		"scalars": map[string]any{
+			"string":   "value",
+			"int":      int64(-42),
+			"float":    2.5,
+			"whole":    float64(3),
+			"large":    1e21,
+			"nan":      math.NaN(),
+			"inf":      math.Inf(1),
+			"ninf":     math.Inf(-1),
+			"bool":     true,
+			"null":     nil,
+			"":         "empty key",
+			"a.b":      "dotted key",
+			"it's":     "quote in key",
+			"unicode":  "ünïcødé",
+			"escapes":  "it's \"quoted\"\n\ttab\r\\ \x01\x7f",
+			"tab only": "a\tb",
+		},
+		"arrays": map[string]any{
+			"empty":  []any{},
+			"mixed":  []any{int64(1), "two", 3.5, []any{true}},
+			"tables": []any{map[string]any{"a": int64(1)}, "b"},
+		},
+		"tables": map[string]any{
+			"value": int64(1),
+			"empty": map[string]any{},
+			"a": map[string]any{
+				"b": map[string]any{
+					"c": "d",
+				},
+				"e": int64(2),
+			},
+			"only": map[string]any{
+				"nested": map[string]any{"x": int64(1)},
+			},
+		},
+		"arrays of tables": map[string]any{
+			"bin": []any{
+				map[string]any{
+					"name": "a",
+					"sub":  map[string]any{"x": int64(1)},
+					"list": []any{map[string]any{"y": int64(2)}},
+				},
+				map[string]any{},
+				map[string]any{"name": "c"},
+			},
+		},
+	}
+
+	for name, value := range testCases {
+		t.Run(name, func(t *testing.T) {
+			expected, err := toml.Marshal(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := encodeToml(value, defaultTomlEncoderOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != string(expected) {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, expected)
+			}
+		})
+	}
+}
+
+func TestEncodeToml_options(t *testing.T) {
+	value := map[string]any{
+		"name": "it's",
+		"tags": []any{"a", []any{int64(1), int64(2)}},
+		"package": map[string]any{
+			"version": "1.0",
+			"metadata": map[string]any{
+				"docs": map[string]any{"all": true},
+			},
+		},
+		"bin": []any{
+			map[string]any{"name": "a", "env": map[string]any{"X": "1"}},
+		},
+	}
+
+	testCases := map[string]struct {
+		options  map[string]any
+		expected string
+		err      bool
+	}{
+		"indentation": {
+			options: map[string]any{"table_indent": "  "},
+			expected: `name = "it's"
+tags = ['a', [1, 2]]
+
+[[bin]]
+  name = 'a'
+
+  [bin.env]
+    X = '1'
+
+[package]
+  version = '1.0'
+
+  [package.metadata]
+    [package.metadata.docs]
+      all = true
+`,
+		},
+		"inline paths": {
+			options: map[string]any{"inline_tables": []any{"package.metadata", "bin.env"}},
+			expected: `name = "it's"
+tags = ['a', [1, 2]]
+
+[[bin]]
+env = {X = '1'}
+name = 'a'
+
+[package]
+metadata = {docs = {all = true}}
+version = '1.0'
+`,
+		},
+		"inline depth": {
+			options: map[string]any{"inline_depth": int64(0)},
+			expected: `bin = [{env = {X = '1'}, name = 'a'}]
+name = "it's"
+package = {metadata = {docs = {all = true}}, version = '1.0'}
+tags = ['a', [1, 2]]
+`,
+		},
+		"multiline arrays": {
+			options: map[string]any{
+				"multiline_arrays": true,
+				"array_indent":     "    ",
+				"table_indent":     "\t",
+				"inline_depth":     int64(1),
+			},
+			expected: `name = "it's"
+tags = [
+    'a',
+    [
+        1,
+        2
+    ]
+]
+
+[[bin]]
+	env = {X = '1'}
+	name = 'a'
+
+[package]
+	metadata = {docs = {all = true}}
+	version = '1.0'
+`,
+		},
+		"basic strings": {
+			options: map[string]any{"quote_style": "basic", "trailing_newline": false, "inline_depth": int64(0)},
+			expected: `bin = [{env = {X = "1"}, name = "a"}]
+name = "it's"
+package = {metadata = {docs = {all = true}}, version = "1.0"}
+tags = ["a", [1, 2]]`,
+		},
+		"null options": {
+			options: map[string]any{"table_indent": nil, "quote_style": nil},
+			expected: `name = "it's"
+tags = ['a', [1, 2]]
+
+[[bin]]
+name = 'a'
+
+[bin.env]
+X = '1'
+
+[package]
+version = '1.0'
+
+[package.metadata]
+[package.metadata.docs]
+all = true
+`,
+		},
+		"key order": {
+			options: map[string]any{
+				"key_order": `# The original document.
+tags = []
+name = 'example'
+other = 1
+
+[package]
+version = '0.1'
+
+[[bin]]
+env = {X = '0', Y = '0'}
+name = 'b'
+
+[[bin]]
+name = 'c'
+`,
+			},
+			expected: `tags = ['a', [1, 2]]
+name = "it's"
+
+[package]
+version = '1.0'
+
+[package.metadata]
+[package.metadata.docs]
+all = true
+
+[[bin]]
+name = 'a'
+
+[bin.env]
+X = '1'
+`,
+		},
+		"key order inline": {
+			options: map[string]any{
+				"key_order":    "bin = [{name = 'b', env = {X = '0'}}]\npackage = {version = '0.1', metadata = {docs = {}}}\n",
+				"inline_depth": int64(0),
+			},
+			expected: `bin = [{name = 'a', env = {X = '1'}}]
+package = {version = '1.0', metadata = {docs = {all = true}}}
+name = "it's"
+tags = ['a', [1, 2]]
+`,
+		},
+		"invalid key order": {
+			options: map[string]any{"key_order": "a = "},
+			err:     true,
+		},
+		"unsupported option": {
+			options: map[string]any{"indent": "  "},
+			err:     true,
+		},
+		"invalid type": {
+			options: map[string]any{"multiline_arrays": "yes"},
+			err:     true,
+		},
+		"invalid quote style": {
+			options: map[string]any{"quote_style": "double"},
+			err:     true,
+		},
+		"invalid path": {
+			options: map[string]any{"inline_tables": []any{"a."}},
+			err:     true,
+		},
+		"invalid inexact numbers": {
+			options: map[string]any{"inexact_numbers": "truncate"},
+			err:     true,
+		},
+		"negative depth": {
+			options: map[string]any{"inline_depth": int64(-1)},
+			err:     true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			options, err := parseTomlEncoderOptions(testCase.options)
+			if testCase.err {
+				if err == nil {
+					t.Fatalf("expected error, got options %+v", options)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := encodeToml(value, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestEncodeToml_taggedValues(t *testing.T) {
+	testCases := map[string]struct {
+		value    map[string]any
+		basic    bool
+		expected string
+		err      bool
+	}{
+		"float": {
+			value:    map[string]any{"__toml_type": "float", "value": int64(2)},
+			expected: "2.0",
+		},
+		"integer": {
+			value:    map[string]any{"__toml_type": "integer", "value": int64(255), "format": "octal"},
+			expected: "0o377",
+		},
+		"integer with invalid format": {
+			value: map[string]any{"__toml_type": "integer", "value": int64(255), "format": "roman"},
+			err:   true,
+		},
+		"multiline literal": {
+			value:    map[string]any{"__toml_type": "string", "value": "a\r\nb", "format": "multiline"},
+			expected: "'''\na\r\nb'''",
+		},
+		"multiline basic": {
+			value:    map[string]any{"__toml_type": "string", "value": "a\\b\"\"\"\x01'"},
+			basic:    true,
+			expected: `"a\\b\"\"\"\u0001'"`,
+		},
+		"multiline basic escapes": {
+			value:    map[string]any{"__toml_type": "string", "value": "a\\b\"\"\"\x01\rend'", "format": "multiline"},
+			expected: "\"\"\"\na\\\\b\\\"\\\"\\\"\\u0001\\rend'\"\"\"",
+		},
+		"local date": {
+			value:    map[string]any{"__toml_type": "local_date", "value": "2024-04-13"},
+			expected: "2024-04-13",
+		},
+		"injected value": {
+			value: map[string]any{"__toml_type": "local_date", "value": "2024-04-13\nb = 1"},
+			err:   true,
+		},
+		"unsupported type": {
+			value: map[string]any{"__toml_type": "complex", "value": "1+2i"},
+			err:   true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			options := defaultTomlEncoderOptions()
+			options.basicStrings = testCase.basic
+			actual, err := encodeToml(map[string]any{"a": testCase.value}, options)
+			if testCase.err {
+				if err == nil {
+					t.Fatalf("expected error, got:\n%s", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := "a = " + testCase.expected + "\n"; string(actual) != expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, expected)
+			}
+
+			// The encoded value must decode to the original value.
+			var decoded map[string]any
+			if err := toml.Unmarshal(actual, &decoded); err != nil {
+				t.Fatalf("result cannot be decoded: %s", err)
+			}
+			if value, ok := testCase.value["value"].(string); ok && testCase.value["__toml_type"] == "string" && decoded["a"] != value {
+				t.Errorf("unexpected decoded value %q, expected %q", decoded["a"], value)
+			}
+		})
+	}
+}
+
+func TestEncodeToml_untaggedTable(t *testing.T) {
+	value := map[string]any{"a": map[string]any{"toml_type": "x", "value": int64(1)}}
+	expected := "[a]\ntoml_type = 'x'\nvalue = 1\n"
+
+	actual, err := encodeToml(value, defaultTomlEncoderOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, expected)
+	}
+
+	var decoded map[string]any
+	if err := toml.Unmarshal(actual, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, value) {
+		t.Errorf("unexpected decoded value %v, expected %v", decoded, value)
+	}
+}
+
+func TestEncodeToml_nullPolicy(t *testing.T) {
+	value := map[string]any{
+		"name":        "example",
+		"description": nil,
+		"tags":        []any{"a", nil, "b"},
+		"bin": []any{
+			nil,
+			map[string]any{"name": "first", "path": nil},
+		},
+		"package": map[string]any{
+			"license":  nil,
+			"metadata": map[string]any{"docs": nil, "all": true},
+		},
+	}
+
+	testCases := map[string]struct {
+		nullPolicy string
+		expected   string
+		err        string
+	}{
+		"omit": {
+			nullPolicy: "omit",
+			expected: `name = 'example'
+tags = ['a', 'b']
+
+[[bin]]
+name = 'first'
+
+[package]
+metadata = {all = true}
+`,
+		},
+		"comment": {
+			nullPolicy: "comment",
+			expected: `# description = null
+name = 'example'
+tags = ['a', 'b']
+
+[[bin]]
+name = 'first'
+# path = null
+
+[package]
+# license = null
+metadata = {all = true}
+`,
+		},
+		"error": {
+			nullPolicy: "error",
+			err:        `the value at bin[0] is null. Set the null_policy option to "omit", "empty" or "comment" to encode it anyway`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			options := defaultTomlEncoderOptions()
+			options.nullPolicy = testCase.nullPolicy
+			options.inlineDepth = 1
+
+			actual, err := encodeToml(value, options)
+			if testCase.err != "" {
+				if err == nil || err.Error() != testCase.err {
+					t.Fatalf("expected error %q, got %v", testCase.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = EqualFunction{}
+)
+
+func NewEqualFunction() function.Function {
+	return EqualFunction{}
+}
+
+type EqualFunction struct{}
+
+func (r EqualFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "equal"
+}
+
+func (r EqualFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Compare the meaning of two TOML documents",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns whether two TOML documents have the same meaning, regardless of their formatting, comments,",
+				"quoting and key order. Unlike comparing decoded values, the comparison is aware of TOML types:",
+				"",
+				"* An integer is equal to a float of the same value, such as `1` and `1.0`.",
+				"* Offset date-times are equal if they are the same instant, such as `1979-05-27T07:32:00Z` and",
+				"  `1979-05-27T00:32:00-07:00`.",
+				"* Times are equal regardless of trailing zeros in their fractional seconds.",
+				"* Dates and times of different types are never equal, nor are they equal to strings.",
+				"",
+				"An optional third argument is an object configuring the comparison. It supports the following",
+				"attributes:",
+				"",
+				"| Attribute          | Description                                                                 |",
+				"|--------------------|-----------------------------------------------------------------------------|",
+				"| `strict_numbers`   | Whether an integer differs from a float of the same value. Default `false`. |",
+				"| `unordered_arrays` | Whether to ignore the order of the elements of arrays. Default `false`.     |",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "a",
+				MarkdownDescription: "First TOML content to compare",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:                "b",
+				MarkdownDescription: "Second TOML content to compare",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the comparison",
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (r EqualFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var a, b string
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &a, &b, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	var options tomlComparisonOptions
+	options, resp.Error = parseComparisonOptionsArgument(optionsArgs, 2)
+	if resp.Error != nil {
+		return
+	}
+
+	var canonical [2]string
+	for i, data := range []string{a, b} {
+		var err error
+		canonical[i], err = canonicalTomlDocument([]byte(data), options)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(
+				int64(i),
+				fmt.Sprintf("The TOML content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(data), "", err)),
+			)
+			return
+		}
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.BoolValue(canonical[0] == canonical[1]))
+}
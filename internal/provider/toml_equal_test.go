@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testEqualConfig = `
+output "numbers" {
+	value = provider::toml::equal("a = 1", "a = 1.0")
+}
+
+output "strict_numbers" {
+	value = provider::toml::equal("a = 1", "a = 1.0", { strict_numbers = true })
+}
+
+output "datetimes" {
+	value = provider::toml::equal("a = 1979-05-27T07:32:00Z", "a = 1979-05-27T00:32:00-07:00")
+}
+
+output "local_datetimes" {
+	value = provider::toml::equal("a = 1979-05-27T07:32:00", "a = 1979-05-27T07:32:00Z")
+}
+
+output "arrays" {
+	value = provider::toml::equal("a = [1, 2]", "a = [2, 1]")
+}
+
+output "unordered_arrays" {
+	value = provider::toml::equal("a = [1, 2]", "a = [2, 1]", { unordered_arrays = true })
+}
+
+output "tables" {
+	value = provider::toml::equal("[a]\nb = 'x' # A comment.\nc = true", "a = { c = true, b = \"x\" }")
+}
+`
+
+	testEqualInvalidDocumentConfig = `
+output "test" {
+	value = provider::toml::equal("a = 1", "a = ")
+}
+`
+
+	testEqualInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::equal("a = 1", "a = 1", { strict_numbers = "yes" })
+}
+`
+)
+
+func TestEqualFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testEqualInvalidDocumentConfig,
+				ExpectError: regexp.MustCompile(`Invalid\s+value\s+for\s+"b"\s+parameter`),
+			},
+			{
+				Config:      testEqualInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`option\s+"strict_numbers"\s+has\s+an\s+invalid\s+type`),
+			},
+			{
+				Config: testEqualConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("numbers", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("strict_numbers", knownvalue.Bool(false)),
+					statecheck.ExpectKnownOutputValue("datetimes", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("local_datetimes", knownvalue.Bool(false)),
+					statecheck.ExpectKnownOutputValue("arrays", knownvalue.Bool(false)),
+					statecheck.ExpectKnownOutputValue("unordered_arrays", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("tables", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
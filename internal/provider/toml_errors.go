@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// tomlErrorContextLines is the number of lines shown before the line on which
+// a decode error occurred.
+const tomlErrorContextLines = 2
+
+// describeTomlDecodeError describes an error returned when decoding a TOML
+// document, adding the line and column at which it occurred, the key being
+// defined, and a snippet of the document pointing at the error. The filename
+// is included if it is not empty.
+func describeTomlDecodeError(data []byte, filename string, err error) string {
+	offset, key, ok := locateTomlDecodeError(data, err)
+	if !ok {
+		return err.Error()
+	}
+
+	line, column := tomlPosition(data, offset)
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+
+	var b strings.Builder
+	b.WriteString(err.Error())
+	fmt.Fprintf(&b, "\n\nAt line %d, column %d", line, column)
+	if filename != "" {
+		fmt.Fprintf(&b, " of %q", filename)
+	}
+	if len(key) > 0 {
+		fmt.Fprintf(&b, " (key %s)", key)
+	}
+	b.WriteString(":\n\n")
+	writeTomlErrorSnippet(&b, data, line, lineStart, offset)
+	return b.String()
+}
+
+// locateTomlDecodeError returns the offset at which an error returned when
+// decoding a TOML document occurred, along with the absolute path of the key
+// being defined, if known.
+//
+// go-toml reports the position of syntax errors, but not of errors such as
+// duplicate keys, which are located by parsing the document again.
+func locateTomlDecodeError(data []byte, err error) (int, tomlPath, bool) {
+	var decodeErr *toml.DecodeError
+	if !errors.As(err, &decodeErr) {
+		// go-toml reports redefinitions as "key a is already defined" or "table
+		// a already exists".
+		if strings.Contains(err.Error(), "already") {
+			return findTomlRedefinition(data)
+		}
+		return 0, nil, false
+	}
+
+	line, column := decodeErr.Position()
+	offset := 0
+	for ; line > 1; line-- {
+		next := bytes.IndexByte(data[offset:], '\n')
+		if next < 0 {
+			return 0, nil, false
+		}
+		offset += next + 1
+	}
+	offset += column - 1
+	if offset > len(data) {
+		return 0, nil, false
+	}
+
+	if key := decodeErr.Key(); len(key) > 0 {
+		var path tomlPath
+		for _, element := range key {
+			path = append(path, keyElement(element))
+		}
+		return offset, path, true
+	}
+	return offset, findTomlErrorKey(data, offset), true
+}
+
+// tomlPosition returns the line and column of an offset within a TOML
+// document. Columns are counted in characters, and both start at 1.
+func tomlPosition(data []byte, offset int) (int, int) {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	line := bytes.Count(data[:lineStart], []byte("\n")) + 1
+	return line, utf8.RuneCount(data[lineStart:offset]) + 1
+}
+
+// tomlExpressionScanner walks the expressions of a TOML document, tracking
+// the current table and the keys and tables which have been defined.
+type tomlExpressionScanner struct {
+	doc         *tomlDocument
+	parser      unstable.Parser
+	arrayTables map[string]int
+	defined     map[string]bool
+	// table is the absolute path of the current table.
+	table tomlPath
+	// key is the absolute path of the last expression if it is a key-value.
+	key tomlPath
+	// end is the offset just after the last expression.
+	end int
+}
+
+func newTomlExpressionScanner(data []byte) *tomlExpressionScanner {
+	s := &tomlExpressionScanner{
+		doc:         &tomlDocument{data: data},
+		arrayTables: make(map[string]int),
+		defined:     make(map[string]bool),
+	}
+	s.parser.Reset(data)
+	return s
+}
+
+// next scans the next expression, returning the offset and absolute path of
+// any key or table which it defines again. It returns false once the end of
+// the document or an error is reached.
+func (s *tomlExpressionScanner) next() (int, tomlPath, bool) {
+	for s.parser.NextExpression() {
+		expr := s.parser.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			section, err := s.doc.parseHeader(expr, s.arrayTables)
+			if err != nil {
+				return 0, nil, false
+			}
+			s.table = section.path
+			s.key = nil
+			s.end = section.headerEnd
+			if expr.Kind == unstable.Table && s.define(section.path) {
+				return s.doc.skipWhitespace(section.headerStart), section.path, true
+			}
+		case unstable.KeyValue:
+			keyValue, err := s.doc.parseKeyValue(expr)
+			if err != nil {
+				return 0, nil, false
+			}
+			s.end = keyValue.end
+			path := s.table.join(keyValue.key...)
+			s.key = path
+			if s.define(path) {
+				return s.doc.skipWhitespace(keyValue.start), path, true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// define records that a key or table has been defined, returning whether it
+// already was.
+func (s *tomlExpressionScanner) define(path tomlPath) bool {
+	key := path.String()
+	defined := s.defined[key]
+	s.defined[key] = true
+	return defined
+}
+
+// findTomlRedefinition returns the offset and absolute path of the first key
+// or table which a document defines more than once.
+func findTomlRedefinition(data []byte) (int, tomlPath, bool) {
+	return newTomlExpressionScanner(data).next()
+}
+
+// findTomlErrorKey returns the absolute path of the key whose key-value
+// contains a syntax error at the given offset, if it can be determined.
+func findTomlErrorKey(data []byte, offset int) tomlPath {
+	// Scan up to the end of the line containing the error.
+	lineEnd := len(data)
+	if next := bytes.IndexByte(data[offset:], '\n'); next >= 0 {
+		lineEnd = offset + next
+	}
+	s := newTomlExpressionScanner(data[:lineEnd])
+	for {
+		if _, _, ok := s.next(); !ok {
+			break
+		}
+	}
+
+	// Values such as malformed numbers are only rejected when decoded, so
+	// the key-value containing the error may have been parsed.
+	if s.end > offset {
+		return s.key
+	}
+
+	// The key-value containing the error starts after the last expression
+	// which was parsed successfully.
+	start := s.doc.skipFiller(s.end)
+	if start >= offset || data[start] == '[' {
+		return nil
+	}
+	separator := bytes.IndexByte(data[start:offset], '=')
+	if separator < 0 {
+		return nil
+	}
+
+	// Parse the key alone, with a placeholder value.
+	candidate := append(bytes.Clone(data[start:start+separator]), "= 0"...)
+	var p unstable.Parser
+	p.Reset(candidate)
+	if !p.NextExpression() || p.Expression().Kind != unstable.KeyValue {
+		return nil
+	}
+	key, _, _, err := (&tomlDocument{data: candidate}).parseKey(p.Expression())
+	if err != nil {
+		return nil
+	}
+	return s.table.join(key...)
+}
+
+// writeTomlErrorSnippet writes the line on which an error occurred, preceded
+// by a few lines of context and followed by a caret pointing at the error.
+func writeTomlErrorSnippet(b *strings.Builder, data []byte, line int, lineStart int, offset int) {
+	lines := strings.Split(string(data[:lineStart]), "\n")
+	lines = lines[:len(lines)-1]
+	if len(lines) > tomlErrorContextLines {
+		lines = lines[len(lines)-tomlErrorContextLines:]
+	}
+	lineEnd := bytes.IndexByte(data[lineStart:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(data) - lineStart
+	}
+	lines = append(lines, string(data[lineStart:lineStart+lineEnd]))
+
+	width := len(fmt.Sprint(line))
+	for i, text := range lines {
+		number := line - len(lines) + 1 + i
+		fmt.Fprintf(b, "%*d | %s\n", width, number, strings.TrimSuffix(text, "\r"))
+	}
+
+	// Tabs are kept so that the caret lines up however they are displayed.
+	var padding strings.Builder
+	for _, r := range string(data[lineStart:offset]) {
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+	fmt.Fprintf(b, "%*s | %s^", width, "", padding.String())
+}
+
+// tomlContentValidator validates that a string is valid TOML content, so that
+// errors in literal content are reported when validating the configuration.
+type tomlContentValidator struct{}
+
+var (
+	_ validator.String                  = tomlContentValidator{}
+	_ function.StringParameterValidator = tomlContentValidator{}
+)
+
+func (v tomlContentValidator) Description(_ context.Context) string {
+	return "value must be valid TOML content"
+}
+
+func (v tomlContentValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v tomlContentValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	content := []byte(req.ConfigValue.ValueString())
+	var decoded any
+	if err := toml.Unmarshal(content, &decoded); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid TOML content",
+			fmt.Sprintf("Attribute %s %s.\n\nOriginal Error: %s", req.Path, v.Description(ctx), describeTomlDecodeError(content, "", err)),
+		)
+	}
+}
+
+func (v tomlContentValidator) ValidateParameterString(ctx context.Context, req function.StringParameterValidatorRequest, resp *function.StringParameterValidatorResponse) {
+	if req.Value.IsNull() || req.Value.IsUnknown() {
+		return
+	}
+
+	content := []byte(req.Value.ValueString())
+	var decoded any
+	if err := toml.Unmarshal(content, &decoded); err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			req.ArgumentPosition,
+			fmt.Sprintf("The TOML content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError(content, "", err)),
+		)
+	}
+}
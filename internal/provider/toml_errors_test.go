@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestDescribeTomlDecodeError(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		filename string
+		expected string
+	}{
+		"syntax error": {
+			document: "a = 1\n[section]\nname =\nb = 2\n",
+			expected: `toml: incomplete number
+
+At line 3, column 7 (key section.name):
+
+1 | a = 1
+2 | [section]
+3 | name =
+  |       ^`,
+		},
+		"filename": {
+			document: "a = \"\\q\"\n",
+			filename: "example.toml",
+			expected: `toml: invalid escaped character U+0071 'q'
+
+At line 1, column 7 of "example.toml" (key a):
+
+1 | a = "\q"
+  |       ^`,
+		},
+		"multiline value": {
+			document: "x = 1\ny = 2\n\n[[servers]]\nports = [\n\t80,\n\t443 443,\n]\n",
+			expected: `toml: array elements must be separated by commas
+
+At line 7, column 6 (key servers[0].ports):
+
+5 | ports = [
+6 | 	80,
+7 | 	443 443,
+  | 	    ^`,
+		},
+		"malformed number": {
+			document: "[package]\nversion = 1.0.0\nname = \"example\"\n",
+			expected: `toml: float can have at most one decimal point
+
+At line 2, column 14 (key package.version):
+
+1 | [package]
+2 | version = 1.0.0
+  |              ^`,
+		},
+		"inline table": {
+			document: "[t]\nb.c = {d = 1, e = }\n",
+			expected: `toml: incomplete number
+
+At line 2, column 19 (key t.b.c):
+
+1 | [t]
+2 | b.c = {d = 1, e = }
+  |                   ^`,
+		},
+		"invalid key": {
+			document: "ünï = 'x'\n",
+			expected: `toml: invalid character at start of key: Ã
+
+At line 1, column 1:
+
+1 | ünï = 'x'
+  | ^`,
+		},
+		"duplicate key": {
+			document: "[[a]]\nx = 1\n[[a]]\nx = 1\n\n[b]\n  c = 1\n  c = 2\n",
+			expected: `toml: key c is already defined
+
+At line 8, column 3 (key b.c):
+
+6 | [b]
+7 |   c = 1
+8 |   c = 2
+  |   ^`,
+		},
+		"duplicate table": {
+			document: "[x]\ny = 1\n[x]\n",
+			expected: `toml: table x already exists
+
+At line 3, column 1 (key x):
+
+1 | [x]
+2 | y = 1
+3 | [x]
+  | ^`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var decoded any
+			err := toml.Unmarshal([]byte(testCase.document), &decoded)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			actual := describeTomlDecodeError([]byte(testCase.document), testCase.filename, err)
+			if actual != testCase.expected {
+				t.Errorf("unexpected description:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
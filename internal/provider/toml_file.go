@@ -6,16 +6,23 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/pelletier/go-toml/v2"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ datasource.DataSource = &TomlFileDataSource{}
+	_ datasource.DataSource                   = &TomlFileDataSource{}
+	_ datasource.DataSourceWithConfigure      = &TomlFileDataSource{}
+	_ datasource.DataSourceWithValidateConfig = &TomlFileDataSource{}
 )
 
 // NewTomlFileDataSource is a helper function to simplify the provider implementation.
@@ -24,7 +31,9 @@ func NewTomlFileDataSource() datasource.DataSource {
 }
 
 // TomlFileDataSource is the data source implementation.
-type TomlFileDataSource struct{}
+type TomlFileDataSource struct {
+	providerData tomlProviderData
+}
 
 // Metadata returns the data source type name.
 func (d *TomlFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -37,13 +46,64 @@ func (d *TomlFileDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 		Description: "The `toml_file` data source allows Terraform to parse TOML file content as a data source.",
 		Attributes: map[string]schema.Attribute{
 			"input": schema.StringAttribute{
-				Description: "Raw content of the TOML file to be parsed.",
-				Required:    true,
+				Description: "Raw content of the TOML file to be parsed. Exactly one of `input` or `filename` " +
+					"must be set. Content known when validating the configuration, such as a literal heredoc, " +
+					"is checked for errors by `terraform validate`.",
+				Optional: true,
+				Validators: []validator.String{
+					tomlContentValidator{},
+				},
+			},
+			"filename": schema.StringAttribute{
+				Description: "Path to the TOML file to be parsed. Relative paths are resolved against the " +
+					"provider's `base_dir`. Exactly one of `input` or `filename` must be set.",
+				Optional: true,
+			},
+			"schema": schema.StringAttribute{
+				Description: "A [JSON Schema](https://json-schema.org/) to validate the content against, given " +
+					"either as JSON content or as the path to a local file. Relative paths are resolved against " +
+					"the provider's `base_dir`. The content is validated in the form of the `content` attribute, " +
+					"and every violation is reported along with the line on which it occurs. Schemas are never " +
+					"loaded over the network.",
+				Optional: true,
+			},
+			"collections": schema.StringAttribute{
+				Description: "How arrays and tables are decoded in the `content` attribute: `structural` (the " +
+					"default) decodes them as tuples and objects, and `homogeneous` decodes them as lists and " +
+					"maps wherever the types of their elements unify, so that they can be used with `for_each` " +
+					"or assigned to variables of type `map(object(...))`. Arrays and tables whose elements cannot " +
+					"be unified are decoded as tuples and objects, with a warning for arrays and tables of tables.",
+				Optional: true,
 			},
 			"content": schema.DynamicAttribute{
 				Description: "Decoded content of the TOML file.",
 				Computed:    true,
 			},
+			"positions": schema.MapNestedAttribute{
+				Description: "Where every key, table and array element is defined within the TOML content, indexed " +
+					"by its path in the same syntax as the `get` function, e.g. `servers.alpha.ip` or `bin[0].name`. " +
+					"Tables which are only defined implicitly, by dotted keys or by the headers of tables within " +
+					"them, are located at the first key or header which defines them.",
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"line": schema.Int64Attribute{
+							Description: "The line on which the key, table header or array element starts.",
+							Computed:    true,
+						},
+						"column": schema.Int64Attribute{
+							Description: "The column at which the key, table header or array element starts, counted " +
+								"in characters.",
+							Computed: true,
+						},
+						"table": schema.StringAttribute{
+							Description: "The header of the table in which it is defined as written, e.g. `[package]` " +
+								"or `[[bin]]`, or `null` for the root table.",
+							Computed: true,
+						},
+					},
+				},
+			},
 			"content_json": schema.StringAttribute{
 				Description: "JSON-encoded content of the TOML file.",
 				Computed:    true,
@@ -51,13 +111,65 @@ func (d *TomlFileDataSource) Schema(_ context.Context, _ datasource.SchemaReques
 					"major version. Use the `content` attribute instead.",
 			},
 			"id": schema.StringAttribute{
-				Description: "The hexadecimal encoding of the SHA1 checksum of the JSON-encoded content.",
+				Description: "The hexadecimal encoding of the SHA1 checksum of the JSON-encoded content. This is not guaranteed to be stable across provider versions; use the `fingerprint` function for a stable checksum of the meaning of the content.",
 				Computed:    true,
 			},
 		},
 	}
 }
 
+// Configure stores the provider data for use when reading the data source.
+func (d *TomlFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(tomlProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected data source configure type",
+			fmt.Sprintf("Expected tomlProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	d.providerData = providerData
+}
+
+// ValidateConfig validates the data source configuration.
+func (d *TomlFileDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var config TomlFileDataSourceModelV0
+
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Collections.IsNull() && !config.Collections.IsUnknown() {
+		if _, err := parseTomlCollections(config.Collections.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("collections"),
+				"Invalid attribute value",
+				fmt.Sprintf("Attribute collections must be one of \"structural\" or \"homogeneous\", got: %q.", config.Collections.ValueString()),
+			)
+		}
+	}
+
+	// Unknown values may turn out to be null, so they can only be checked once known.
+	if config.Input.IsUnknown() || config.Filename.IsUnknown() {
+		return
+	}
+
+	if config.Input.IsNull() == config.Filename.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("input"),
+			"Invalid attribute combination",
+			"Exactly one of `input` or `filename` must be set.",
+		)
+	}
+}
+
 // Read refreshes the Terraform state with the latest data.
 func (d *TomlFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var config TomlFileDataSourceModelV0
@@ -68,18 +180,47 @@ func (d *TomlFileDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
+	input := []byte(config.Input.ValueString())
+	source := "The TOML file content"
+	filename := ""
+	attributePath := path.Root("input")
+	if !config.Filename.IsNull() {
+		filename = d.providerData.resolvePath(config.Filename.ValueString())
+		attributePath = path.Root("filename")
+		fileContent, err := os.ReadFile(filename)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filename"),
+				"Read TOML file data source error",
+				fmt.Sprintf("The TOML file %q cannot be read.\n\n", filename)+
+					fmt.Sprintf("Original Error: %s", err),
+			)
+			return
+		}
+		input = fileContent
+		source = fmt.Sprintf("The TOML file %q", filename)
+	}
+
 	var decodedContent interface{}
-	err := toml.Unmarshal([]byte(config.Input.ValueString()), &decodedContent)
+	err := toml.Unmarshal(input, &decodedContent)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.AddAttributeError(
+			attributePath,
 			"Read TOML file data source error",
-			"The TOML file content cannot be decoded.\n\n"+
-				fmt.Sprintf("Original Error: %s", err),
+			source+" cannot be decoded.\n\n"+
+				fmt.Sprintf("Original Error: %s", describeTomlDecodeError(input, filename, err)),
 		)
 		return
 	}
 
-	jsonContent, err := json.Marshal(decodedContent)
+	if !config.Schema.IsNull() {
+		checkTomlSchema(config.Schema.ValueString(), d.providerData.baseDir, input, attributePath, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	jsonContent, err := json.Marshal(tagSpecialFloats(decodedContent))
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Read TOML file data source error",
@@ -95,12 +236,44 @@ func (d *TomlFileDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
+	if config.Collections.ValueString() == "homogeneous" {
+		var fallbacks []tomlPath
+		tfContent = homogenizeTerraformValue(nil, tfContent, &fallbacks)
+		if len(fallbacks) > 0 {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("collections"),
+				"Inconsistent element types",
+				formatHomogenizeFallbacks(fallbacks),
+			)
+		}
+	}
+
+	doc, err := parseTomlDocument(input)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			attributePath,
+			"Read TOML file data source error",
+			source+" cannot be parsed.\n\n"+
+				fmt.Sprintf("Original Error: %s", describeTomlDecodeError(input, filename, err)),
+		)
+		return
+	}
+	positions, diags := newTomlPositionsValue(doc)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	sha1Sum := sha1.Sum(jsonContent)
 	sha1Hex := hex.EncodeToString(sha1Sum[:])
 
 	state := TomlFileDataSourceModelV0{
 		Input:       config.Input,
+		Filename:    config.Filename,
+		Schema:      config.Schema,
+		Collections: config.Collections,
 		Content:     types.DynamicValue(tfContent),
+		Positions:   positions,
 		ContentJSON: types.StringValue(string(jsonContent)),
 		ID:          types.StringValue(sha1Hex),
 	}
@@ -111,7 +284,37 @@ func (d *TomlFileDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 type TomlFileDataSourceModelV0 struct {
 	Input       types.String  `tfsdk:"input"`
+	Filename    types.String  `tfsdk:"filename"`
+	Schema      types.String  `tfsdk:"schema"`
+	Collections types.String  `tfsdk:"collections"`
 	Content     types.Dynamic `tfsdk:"content"`
+	Positions   types.Map     `tfsdk:"positions"`
 	ContentJSON types.String  `tfsdk:"content_json"`
 	ID          types.String  `tfsdk:"id"`
 }
+
+// tomlPositionAttrTypes are the attribute types of the objects in the positions
+// attribute.
+var tomlPositionAttrTypes = map[string]attr.Type{
+	"line":   types.Int64Type,
+	"column": types.Int64Type,
+	"table":  types.StringType,
+}
+
+// newTomlPositionsValue returns the positions attribute for a document.
+func newTomlPositionsValue(doc *tomlDocument) (types.Map, diag.Diagnostics) {
+	elements := make(map[string]attr.Value)
+	for path, position := range doc.positions() {
+		line, column := tomlPosition(doc.data, position.offset)
+		table := types.StringNull()
+		if position.section.header != "" {
+			table = types.StringValue(position.section.header)
+		}
+		elements[path] = types.ObjectValueMust(tomlPositionAttrTypes, map[string]attr.Value{
+			"line":   types.Int64Value(int64(line)),
+			"column": types.Int64Value(int64(column)),
+			"table":  table,
+		})
+	}
+	return types.MapValue(types.ObjectType{AttrTypes: tomlPositionAttrTypes}, elements)
+}
@@ -0,0 +1,563 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	defaultFilePermission      = "0644"
+	defaultDirectoryPermission = "0755"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &TomlFileResource{}
+	_ resource.ResourceWithConfigure   = &TomlFileResource{}
+	_ resource.ResourceWithImportState = &TomlFileResource{}
+	_ resource.ResourceWithModifyPlan  = &TomlFileResource{}
+)
+
+// NewTomlFileResource is a helper function to simplify the provider implementation.
+func NewTomlFileResource() resource.Resource {
+	return &TomlFileResource{}
+}
+
+// TomlFileResource is the resource implementation.
+type TomlFileResource struct {
+	providerData tomlProviderData
+}
+
+// Metadata returns the resource type name.
+func (r *TomlFileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+// Schema defines the schema for the resource.
+func (r *TomlFileResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The `toml_file` resource encodes a value as TOML and writes it to a file. The resource " +
+			"owns the whole file, so any change made to it outside of Terraform is corrected, including changes " +
+			"to its formatting or comments.",
+		Attributes: map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path to the file that will be created. Relative paths are resolved against the " +
+					"provider's `base_dir`. Missing parent directories will be created. Changing this forces the " +
+					"file to be recreated.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"absolute_path": schema.StringAttribute{
+				Description: "The absolute path of the file, resolved from `filename` and the provider's `base_dir`. " +
+					"Changing this, for example by changing `base_dir`, forces the file to be recreated.",
+				Computed: true,
+			},
+			"content": schema.DynamicAttribute{
+				Description: "Value to encode as TOML and write to the file. The value is encoded in the same " +
+					"way as the `encode` function.",
+				Required: true,
+			},
+			"file_permission": schema.StringAttribute{
+				Description: "Permissions to set for the file, as an octal string. Defaults to `" +
+					defaultFilePermission + "`.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(defaultFilePermission),
+				Validators: []validator.String{
+					filePermissionValidator{},
+				},
+			},
+			"directory_permission": schema.StringAttribute{
+				Description: "Permissions to set for any directories created, as an octal string. Defaults to `" +
+					defaultDirectoryPermission + "`. Changing this forces the file to be recreated.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(defaultDirectoryPermission),
+				Validators: []validator.String{
+					filePermissionValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"schema": schema.StringAttribute{
+				Description: "A [JSON Schema](https://json-schema.org/) to validate the content against when " +
+					"planning, given either as JSON content or as the path to a local file. Relative paths are " +
+					"resolved against the provider's `base_dir`. The content is validated in the form returned by " +
+					"the `decode` function. Schemas are never loaded over the network.",
+				Optional: true,
+			},
+			"content_md5": schema.StringAttribute{
+				Description: "MD5 checksum of the file content.",
+				Computed:    true,
+			},
+			"content_sha1": schema.StringAttribute{
+				Description: "SHA1 checksum of the file content.",
+				Computed:    true,
+			},
+			"content_sha256": schema.StringAttribute{
+				Description: "SHA256 checksum of the file content.",
+				Computed:    true,
+			},
+			"content_base64sha256": schema.StringAttribute{
+				Description: "Base64 encoded SHA256 checksum of the file content.",
+				Computed:    true,
+			},
+			"content_sha512": schema.StringAttribute{
+				Description: "SHA512 checksum of the file content.",
+				Computed:    true,
+			},
+			"content_base64sha512": schema.StringAttribute{
+				Description: "Base64 encoded SHA512 checksum of the file content.",
+				Computed:    true,
+			},
+			"id": schema.StringAttribute{
+				Description: "The hexadecimal encoding of the SHA1 checksum of the file content.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure stores the provider data for use when managing the file.
+func (r *TomlFileResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(tomlProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected resource configure type",
+			fmt.Sprintf("Expected tomlProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+// ModifyPlan plans the checksums of the encoded content, and validates it
+// against the schema, if one is set.
+func (r *TomlFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed.
+		return
+	}
+
+	var plan TomlFileResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Filename.IsUnknown() {
+		absolutePath, err := r.providerData.absolutePath(plan.Filename.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filename"),
+				"Plan TOML file resource error",
+				fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+			)
+			return
+		}
+		plan.AbsolutePath = types.StringValue(absolutePath)
+
+		// A relative filename refers to another file when the base directory
+		// changes, in which case the file at the old path must be removed.
+		if !req.State.Raw.IsNull() {
+			var state TomlFileResourceModelV0
+
+			diags = req.State.Get(ctx, &state)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			if !state.AbsolutePath.IsNull() && !state.AbsolutePath.Equal(plan.AbsolutePath) {
+				resp.RequiresReplace = append(resp.RequiresReplace, path.Root("absolute_path"))
+			}
+		}
+
+		diags = resp.Plan.Set(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// The content can only be encoded once it is fully known.
+	if !isFullyKnown(ctx, plan.Content) {
+		return
+	}
+
+	encodedContent, err := encodeTerraformValue(plan.Content)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Plan TOML file resource error",
+			fmt.Sprintf("The value cannot be encoded to TOML.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	// Read records the checksums of the file on disk, so planning those of the
+	// exact bytes to be written corrects changes made outside of Terraform to
+	// the formatting, comments or key order of the file, which do not change
+	// the decoded content.
+	plan.setChecksums(encodedContent)
+	diags = resp.Plan.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Schema.IsNull() || plan.Schema.IsUnknown() {
+		return
+	}
+
+	checkTomlSchema(plan.Schema.ValueString(), r.providerData.baseDir, encodedContent, path.Root("content"), &resp.Diagnostics)
+}
+
+// Create encodes the content and writes it to the file.
+func (r *TomlFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan TomlFileResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(&plan, true, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the content of the file on disk.
+func (r *TomlFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state TomlFileResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := r.stateFilename(state)
+
+	fileInfo, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The file has been removed outside of Terraform, so it must be recreated.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML file resource error",
+			fmt.Sprintf("The file %q cannot be read.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	fileContent, err := os.ReadFile(filename)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML file resource error",
+			fmt.Sprintf("The file %q cannot be read.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	// The content is only null in state when the resource is being imported.
+	importing := state.Content.IsNull()
+
+	var expectedContent []byte
+	if !importing {
+		expectedContent, err = encodeTerraformValue(state.Content)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Read TOML file resource error",
+				fmt.Sprintf("The content in state cannot be encoded to TOML.\n\nOriginal Error: %s", err),
+			)
+			return
+		}
+	}
+
+	if importing || !bytes.Equal(fileContent, expectedContent) {
+		// The file has been modified outside of Terraform (or is being imported),
+		// so record its actual content and let Terraform plan an update.
+		var decodedContent any
+		if err := toml.Unmarshal(fileContent, &decodedContent); err != nil {
+			if importing {
+				resp.Diagnostics.AddError(
+					"Read TOML file resource error",
+					fmt.Sprintf("The file %q cannot be decoded.\n\nOriginal Error: %s", filename, describeTomlDecodeError(fileContent, filename, err)),
+				)
+				return
+			}
+			// The file is no longer valid TOML, so it must be recreated.
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		_, tfContent, diags := convertToTerraformType(decodedContent)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Content = types.DynamicValue(tfContent)
+	}
+
+	state.FilePermission = types.StringValue(formatPermission(fileInfo.Mode()))
+	if state.DirectoryPermission.IsNull() {
+		// Only happens when importing, in which case the best we can do is to use the
+		// permissions of the directory the file is already in.
+		dirInfo, err := os.Stat(filepath.Dir(filename))
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Read TOML file resource error",
+				fmt.Sprintf("The directory of file %q cannot be read.\n\nOriginal Error: %s", filename, err),
+			)
+			return
+		}
+		state.DirectoryPermission = types.StringValue(formatPermission(dirInfo.Mode()))
+	}
+
+	if state.AbsolutePath.IsNull() {
+		// Only happens when importing, or when the state was written by an
+		// earlier version of the provider.
+		absolutePath, err := filepath.Abs(filename)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Read TOML file resource error",
+				fmt.Sprintf("The path of file %q cannot be resolved.\n\nOriginal Error: %s", filename, err),
+			)
+			return
+		}
+		state.AbsolutePath = types.StringValue(absolutePath)
+	}
+
+	state.setChecksums(fileContent)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update re-encodes the content and rewrites the file.
+func (r *TomlFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan TomlFileResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.write(&plan, false, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the file.
+func (r *TomlFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state TomlFileResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := r.stateFilename(state)
+
+	err := os.Remove(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError(
+			"Delete TOML file resource error",
+			fmt.Sprintf("The file %q cannot be removed.\n\nOriginal Error: %s", filename, err),
+		)
+	}
+}
+
+// ImportState imports an existing file, using its path as the import identifier.
+func (r *TomlFileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("filename"), req, resp)
+}
+
+// stateFilename returns the path of the file recorded in state, which is
+// where the file was written even if the base directory has since changed.
+func (r *TomlFileResource) stateFilename(state TomlFileResourceModelV0) string {
+	if !state.AbsolutePath.IsNull() && !state.AbsolutePath.IsUnknown() {
+		return state.AbsolutePath.ValueString()
+	}
+	return r.providerData.resolvePath(state.Filename.ValueString())
+}
+
+// write encodes the planned content, writes it to the file and sets the computed
+// attributes of the plan accordingly.
+func (r *TomlFileResource) write(plan *TomlFileResourceModelV0, create bool, diags *diag.Diagnostics) {
+	filename, err := r.providerData.absolutePath(plan.Filename.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("filename"),
+			"Write TOML file resource error",
+			fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+	plan.AbsolutePath = types.StringValue(filename)
+
+	encodedContent, err := encodeTerraformValue(plan.Content)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("content"),
+			"Write TOML file resource error",
+			fmt.Sprintf("The value cannot be encoded to TOML.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	// Permissions have already been validated, so they can be parsed safely.
+	filePermission, _ := parsePermission(plan.FilePermission.ValueString())
+	directoryPermission, _ := parsePermission(plan.DirectoryPermission.ValueString())
+
+	if create {
+		if err := os.MkdirAll(filepath.Dir(filename), directoryPermission); err != nil {
+			diags.AddError(
+				"Write TOML file resource error",
+				fmt.Sprintf("The directory of file %q cannot be created.\n\nOriginal Error: %s", filename, err),
+			)
+			return
+		}
+	}
+
+	if err := os.WriteFile(filename, encodedContent, filePermission); err != nil {
+		diags.AddError(
+			"Write TOML file resource error",
+			fmt.Sprintf("The file %q cannot be written.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	// Permissions given to os.WriteFile are subject to the umask, and are ignored
+	// altogether for existing files.
+	if err := os.Chmod(filename, filePermission); err != nil {
+		diags.AddError(
+			"Write TOML file resource error",
+			fmt.Sprintf("The permissions of file %q cannot be set.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	plan.setChecksums(encodedContent)
+}
+
+type TomlFileResourceModelV0 struct {
+	Filename            types.String  `tfsdk:"filename"`
+	AbsolutePath        types.String  `tfsdk:"absolute_path"`
+	Content             types.Dynamic `tfsdk:"content"`
+	FilePermission      types.String  `tfsdk:"file_permission"`
+	DirectoryPermission types.String  `tfsdk:"directory_permission"`
+	Schema              types.String  `tfsdk:"schema"`
+	ContentMD5          types.String  `tfsdk:"content_md5"`
+	ContentSHA1         types.String  `tfsdk:"content_sha1"`
+	ContentSHA256       types.String  `tfsdk:"content_sha256"`
+	ContentBase64SHA256 types.String  `tfsdk:"content_base64sha256"`
+	ContentSHA512       types.String  `tfsdk:"content_sha512"`
+	ContentBase64SHA512 types.String  `tfsdk:"content_base64sha512"`
+	ID                  types.String  `tfsdk:"id"`
+}
+
+// setChecksums sets the checksum attributes of the model for the given file content.
+func (m *TomlFileResourceModelV0) setChecksums(content []byte) {
+	md5Sum := md5.Sum(content)
+	sha1Sum := sha1.Sum(content)
+	sha256Sum := sha256.Sum256(content)
+	sha512Sum := sha512.Sum512(content)
+
+	m.ContentMD5 = types.StringValue(hex.EncodeToString(md5Sum[:]))
+	m.ContentSHA1 = types.StringValue(hex.EncodeToString(sha1Sum[:]))
+	m.ContentSHA256 = types.StringValue(hex.EncodeToString(sha256Sum[:]))
+	m.ContentBase64SHA256 = types.StringValue(base64.StdEncoding.EncodeToString(sha256Sum[:]))
+	m.ContentSHA512 = types.StringValue(hex.EncodeToString(sha512Sum[:]))
+	m.ContentBase64SHA512 = types.StringValue(base64.StdEncoding.EncodeToString(sha512Sum[:]))
+	m.ID = m.ContentSHA1
+}
+
+func parsePermission(permission string) (fs.FileMode, error) {
+	mode, err := strconv.ParseUint(permission, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mode > uint64(fs.ModePerm) {
+		return 0, fmt.Errorf("permission %q is out of range", permission)
+	}
+	return fs.FileMode(mode), nil
+}
+
+func formatPermission(mode fs.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+// filePermissionValidator validates that a string is a valid octal file permission.
+type filePermissionValidator struct{}
+
+var _ validator.String = filePermissionValidator{}
+
+func (v filePermissionValidator) Description(_ context.Context) string {
+	return "value must be a file permission in octal notation, e.g. \"0644\""
+}
+
+func (v filePermissionValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v filePermissionValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parsePermission(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid file permission",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
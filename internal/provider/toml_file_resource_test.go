@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+const (
+	testAccTomlFileResourceConfig = `
+resource "toml_file" "file" {
+  filename = %q
+  content = {
+    name = "go-toml"
+    tags = ["go", "toml"]
+    section = {
+      version = 2
+    }
+  }
+}
+`
+
+	testAccTomlFileResourceExpectedContent = `name = 'go-toml'
+tags = ['go', 'toml']
+
+[section]
+version = 2
+`
+
+	testAccTomlFileResourceUnknownConfig = `
+resource "terraform_data" "computed" {
+  input = "example"
+}
+
+resource "toml_file" "file" {
+  filename = %q
+  content = {
+    name = terraform_data.computed.output
+  }
+  schema = jsonencode({
+    type = "object"
+    properties = {
+      name = { type = "string" }
+    }
+  })
+}
+`
+
+	testAccTomlFileResourceBaseDirConfig = `
+provider "toml" {
+  base_dir = %q
+}
+
+resource "toml_file" "file" {
+  filename = "example.toml"
+  content = {
+    name = "example"
+  }
+}
+`
+
+	testAccTomlFileResourceSchemaConfig = `
+resource "toml_file" "file" {
+  filename = %q
+  content = {
+    name = %s
+  }
+  schema = jsonencode({
+    type = "object"
+    properties = {
+      name = { type = "string" }
+    }
+  })
+}
+`
+)
+
+func TestAccTomlFileResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "nested", "example.toml")
+	config := fmt.Sprintf(testAccTomlFileResourceConfig, filename)
+
+	sha1Sum := sha1.Sum([]byte(testAccTomlFileResourceExpectedContent))
+	sha1Hex := hex.EncodeToString(sha1Sum[:])
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create testing.
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, testAccTomlFileResourceExpectedContent),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_file.file",
+						tfjsonpath.New("file_permission"),
+						knownvalue.StringExact("0644"),
+					),
+					statecheck.ExpectKnownValue(
+						"toml_file.file",
+						tfjsonpath.New("content_sha1"),
+						knownvalue.StringExact(sha1Hex),
+					),
+					statecheck.ExpectKnownValue(
+						"toml_file.file",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(sha1Hex),
+					),
+				},
+			},
+			// Drift testing: the file is modified outside of Terraform.
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filename, []byte("name = 'modified'\n"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testAccCheckFileContent(filename, testAccTomlFileResourceExpectedContent),
+			},
+			// Drift testing: the file is reformatted outside of Terraform, without
+			// changing its decoded content.
+			{
+				PreConfig: func() {
+					content := "# Reformatted.\ntags = [\"go\", \"toml\"]\nname = \"go-toml\"\nsection.version = 2\n"
+					if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("toml_file.file", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testAccCheckFileContent(filename, testAccTomlFileResourceExpectedContent),
+			},
+			// Drift testing: the file is removed outside of Terraform.
+			{
+				PreConfig: func() {
+					if err := os.Remove(filename); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testAccCheckFileContent(filename, testAccTomlFileResourceExpectedContent),
+			},
+			// Import testing.
+			{
+				ResourceName:                         "toml_file.file",
+				ImportState:                          true,
+				ImportStateId:                        filename,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "filename",
+				// The imported content is decoded from the file, so its type differs
+				// from the type of the configured value.
+				ImportStateVerifyIgnore: []string{"content"},
+			},
+		},
+	})
+}
+
+// testAccCheckFileContent checks that the file at the given path has the expected content.
+func testAccCheckFileContent(filename string, expectedContent string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if string(content) != expectedContent {
+			return fmt.Errorf("unexpected content of file %q:\n%s\nexpected:\n%s", filename, content, expectedContent)
+		}
+		return nil
+	}
+}
+
+func TestAccTomlFileResource_schema(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "example.toml")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The content is validated before the file is written.
+			{
+				Config:             fmt.Sprintf(testAccTomlFileResourceSchemaConfig, filename, "1"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+				ExpectError:        regexp.MustCompile(`does\s+not\s+match\s+the\s+JSON\s+Schema\s+at\s+name\s+\(line\s+1\)`),
+			},
+			{
+				Config: fmt.Sprintf(testAccTomlFileResourceSchemaConfig, filename, `"example"`),
+				Check:  testAccCheckFileContent(filename, "name = 'example'\n"),
+			},
+		},
+	})
+}
+
+func TestAccTomlFileResource_unknownContent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "example.toml")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Content computed during apply is neither validated nor encoded when planning.
+			{
+				Config: fmt.Sprintf(testAccTomlFileResourceUnknownConfig, filename),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue("toml_file.file", tfjsonpath.New("content").AtMapKey("name")),
+						plancheck.ExpectUnknownValue("toml_file.file", tfjsonpath.New("content_sha1")),
+					},
+				},
+				Check: testAccCheckFileContent(filename, "name = 'example'\n"),
+			},
+		},
+	})
+}
+
+func TestAccTomlFileResource_baseDir(t *testing.T) {
+	oldBaseDir := t.TempDir()
+	newBaseDir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTomlFileResourceBaseDirConfig, oldBaseDir),
+				Check:  testAccCheckFileContent(filepath.Join(oldBaseDir, "example.toml"), "name = 'example'\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_file.file",
+						tfjsonpath.New("absolute_path"),
+						knownvalue.StringExact(filepath.Join(oldBaseDir, "example.toml")),
+					),
+				},
+			},
+			// Changing the base directory moves the file.
+			{
+				Config: fmt.Sprintf(testAccTomlFileResourceBaseDirConfig, newBaseDir),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("toml_file.file", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFileContent(filepath.Join(newBaseDir, "example.toml"), "name = 'example'\n"),
+					func(_ *terraform.State) error {
+						if _, err := os.Stat(filepath.Join(oldBaseDir, "example.toml")); !os.IsNotExist(err) {
+							return fmt.Errorf("the file at the old path was not removed: %v", err)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
@@ -8,6 +8,11 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
@@ -104,8 +109,232 @@ func TestAccTomlFileDataSource(t *testing.T) {
 						tfjsonpath.New("id"),
 						knownvalue.StringExact(sha1Hex),
 					),
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("positions").AtMapKey("version"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"line":   knownvalue.Int64Exact(1),
+							"column": knownvalue.Int64Exact(1),
+							"table":  knownvalue.Null(),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("positions").AtMapKey("section"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"line":   knownvalue.Int64Exact(5),
+							"column": knownvalue.Int64Exact(1),
+							"table":  knownvalue.StringExact("[section.subsection]"),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("positions").AtMapKey("section.subsection.items[0].include"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"line":   knownvalue.Int64Exact(7),
+							"column": knownvalue.Int64Exact(3),
+							"table":  knownvalue.StringExact("[section.subsection]"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+const (
+	testAccTomlFileDataSourceFilenameConfig = `
+provider "toml" {
+  base_dir = %q
+}
+
+data "toml_file" "file" {
+  filename = "example.toml"
+}
+`
+
+	testAccTomlFileDataSourceSchemaConfig = `
+provider "toml" {
+  base_dir = %q
+}
+
+data "toml_file" "file" {
+  input  = %q
+  schema = "schema.json"
+}
+`
+
+	testAccTomlFileDataSourceSchema = `{
+  "type": "object",
+  "properties": {
+    "version": {"type": "integer"},
+    "name": {"type": "string"}
+  }
+}`
+
+	testAccTomlFileDataSourceInvalidConfig = `
+data "toml_file" "file" {
+  input    = "version = 2"
+  filename = "example.toml"
+}
+`
+)
+
+func TestAccTomlFileDataSource_filename(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "example.toml"), []byte("version = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "invalid.toml"), []byte("version = \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTomlFileDataSourceInvalidConfig,
+				ExpectError: regexp.MustCompile("Exactly one of `input` or `filename` must\\s+be\\s+set"),
+			},
+			{
+				Config: strings.Replace(
+					fmt.Sprintf(testAccTomlFileDataSourceFilenameConfig, baseDir), "example.toml", "invalid.toml", 1,
+				),
+				ExpectError: regexp.MustCompile(`(?s)The TOML file\s+".*invalid\.toml"\s+cannot\s+be\s+decoded.*At\s+line\s+1,\s+column\s+11\s+of\s+".*invalid\.toml"\s+\(key\s+version\)`),
+			},
+			{
+				Config:      fmt.Sprintf(testAccTomlFileDataSourceFilenameConfig, filepath.Join(baseDir, "missing")),
+				ExpectError: regexp.MustCompile(`The TOML file\s+".*missing/example\.toml"\s+cannot\s+be\s+read`),
+			},
+			{
+				Config: fmt.Sprintf(testAccTomlFileDataSourceFilenameConfig, baseDir),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("content"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"version": knownvalue.Int64Exact(2),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("input"),
+						knownvalue.Null(),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAccTomlFileDataSource_schema(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "schema.json"), []byte(testAccTomlFileDataSourceSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccTomlFileDataSourceSchemaConfig, baseDir, "version = 2\nname = 3\n"),
+				ExpectError: regexp.MustCompile(`does\s+not\s+match\s+the\s+JSON\s+Schema\s+at\s+name\s+\(line\s+2\):\s+expected\s+string`),
+			},
+			{
+				Config: fmt.Sprintf(testAccTomlFileDataSourceSchemaConfig, baseDir, "version = 2\nname = \"example\"\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("content"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"version": knownvalue.Int64Exact(2),
+							"name":    knownvalue.StringExact("example"),
+						}),
+					),
 				},
 			},
 		},
 	})
 }
+
+const (
+	testAccTomlFileDataSourceCollectionsConfig = `
+data "toml_file" "file" {
+  input       = %q
+  collections = %q
+}
+`
+
+	testAccTomlFileDataSourceCollectionsInput = `ports = [80, 443.5]
+
+[[bin]]
+name = "a"
+
+[[bin]]
+name = "b"
+test = true
+`
+)
+
+func TestAccTomlFileDataSource_collections(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccTomlFileDataSourceCollectionsConfig, "a = 1", "lists"),
+				ExpectError: regexp.MustCompile(`Attribute collections must be one of "structural" or\s+"homogeneous"`),
+			},
+			{
+				Config: fmt.Sprintf(testAccTomlFileDataSourceCollectionsConfig, testAccTomlFileDataSourceCollectionsInput, "homogeneous"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.toml_file.file",
+						tfjsonpath.New("content"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"ports": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.Int64Exact(80),
+								knownvalue.Float64Exact(443.5),
+							}),
+							"bin": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.MapExact(map[string]knownvalue.Check{
+									"name": knownvalue.StringExact("a"),
+								}),
+								knownvalue.ObjectExact(map[string]knownvalue.Check{
+									"name": knownvalue.StringExact("b"),
+									"test": knownvalue.Bool(true),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+const testAccTomlFileDataSourceSyntaxErrorConfig = `
+data "toml_file" "file" {
+  input = <<EOF
+[package]
+name = "example"
+version = 1.0.0
+EOF
+}
+`
+
+func TestAccTomlFileDataSource_syntaxError(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Literal content is checked when validating the configuration.
+			{
+				Config:      testAccTomlFileDataSourceSyntaxErrorConfig,
+				ExpectError: regexp.MustCompile(`(?s)Invalid TOML content.*At\s+line\s+3,\s+column\s+14\s+\(key\s+package\.version\)`),
+			},
+		},
+	})
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	_ function.Function = FingerprintFunction{}
+)
+
+// tomlComparisonOptions control which TOML documents are considered equal by
+// the equal and fingerprint functions.
+type tomlComparisonOptions struct {
+	// strictNumbers distinguishes integers from floats of the same value.
+	strictNumbers bool
+	// unorderedArrays ignores the order of the elements of arrays.
+	unorderedArrays bool
+}
+
+// parseTomlComparisonOptions parses the options argument of the equal and
+// fingerprint functions, as returned by convertFromTerraformType.
+func parseTomlComparisonOptions(options any) (tomlComparisonOptions, error) {
+	result := tomlComparisonOptions{}
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return result, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "strict_numbers":
+			result.strictNumbers, ok = value.(bool)
+		case "unordered_arrays":
+			result.unorderedArrays, ok = value.(bool)
+		default:
+			return result, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return result, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+	return result, nil
+}
+
+// parseComparisonOptionsArgument parses the variadic options argument of the
+// equal and fingerprint functions, which is at the given position.
+func parseComparisonOptionsArgument(optionsArgs []types.Dynamic, position int64) (tomlComparisonOptions, *function.FuncError) {
+	options := tomlComparisonOptions{}
+	if len(optionsArgs) > 1 {
+		return options, function.NewArgumentFuncError(
+			position+1,
+			"At most one options argument may be given",
+		)
+	}
+	if len(optionsArgs) == 1 {
+		optionsValue, err := convertFromTerraformType(optionsArgs[0].UnderlyingValue())
+		if err == nil {
+			options, err = parseTomlComparisonOptions(optionsValue)
+		}
+		if err != nil {
+			return options, function.NewArgumentFuncError(
+				position,
+				fmt.Sprintf("The comparison options are invalid.\n\nOriginal Error: %s", err),
+			)
+		}
+	}
+	return options, nil
+}
+
+// canonicalTomlDocument decodes TOML content and returns its canonical
+// encoding, which is the same for documents with the same meaning.
+func canonicalTomlDocument(data []byte, options tomlComparisonOptions) (string, error) {
+	var decoded any
+	if err := toml.Unmarshal(data, &decoded); err != nil {
+		return "", err
+	}
+	return canonicalTomlValue(decoded, options), nil
+}
+
+// canonicalTomlValue returns the canonical encoding of a decoded TOML value.
+// It is written in TOML inline syntax without whitespace: tables with their
+// keys sorted and quoted, strings as basic strings, integers in decimal and
+// floats in their shortest form. Unless strictNumbers is set, whole floats
+// are written as integers. Offset date-times are converted to UTC, and the
+// fractional seconds of all times are written without trailing zeros.
+func canonicalTomlValue(value any, options tomlComparisonOptions) string {
+	e := &tomlEncoder{}
+	switch value := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		e.b = append(e.b, '{')
+		for i, key := range keys {
+			if i > 0 {
+				e.b = append(e.b, ',')
+			}
+			e.encodeBasicString(key)
+			e.b = append(e.b, '=')
+			e.b = append(e.b, canonicalTomlValue(value[key], options)...)
+		}
+		e.b = append(e.b, '}')
+	case []any:
+		elements := make([]string, len(value))
+		for i, element := range value {
+			elements[i] = canonicalTomlValue(element, options)
+		}
+		if options.unorderedArrays {
+			sort.Strings(elements)
+		}
+		e.b = append(e.b, '[')
+		e.b = append(e.b, strings.Join(elements, ",")...)
+		e.b = append(e.b, ']')
+	case string:
+		e.encodeBasicString(value)
+	case bool:
+		e.b = strconv.AppendBool(e.b, value)
+	case int64:
+		e.b = strconv.AppendInt(e.b, value, 10)
+	case float64:
+		if value == 0 {
+			// Negative zero is equal to zero.
+			value = 0
+		}
+		if !options.strictNumbers && math.Trunc(value) == value && value >= math.MinInt64 && value < math.MaxInt64 {
+			return strconv.FormatInt(int64(value), 10)
+		}
+		e.encodeFloat(value)
+	case time.Time:
+		e.b = append(e.b, value.UTC().Format(time.RFC3339Nano)...)
+	case toml.LocalDateTime:
+		value.Precision = 0
+		e.b = append(e.b, value.String()...)
+	case toml.LocalDate:
+		e.b = append(e.b, value.String()...)
+	case toml.LocalTime:
+		value.Precision = 0
+		e.b = append(e.b, value.String()...)
+	default:
+		e.b = append(e.b, fmt.Sprint(value)...)
+	}
+	return string(e.b)
+}
+
+func NewFingerprintFunction() function.Function {
+	return FingerprintFunction{}
+}
+
+type FingerprintFunction struct{}
+
+func (r FingerprintFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "fingerprint"
+}
+
+func (r FingerprintFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Compute a fingerprint of the meaning of a TOML document",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns the hexadecimal SHA-256 checksum of a canonical encoding of a TOML document, which only",
+				"changes when the meaning of the document changes, and not when its formatting, comments or key",
+				"order do. This makes it suitable for `replace_triggered_by`. Two documents have the same",
+				"fingerprint exactly when the `equal` function considers them equal with the same options.",
+				"",
+				"The canonical encoding is the document written as a TOML inline table without whitespace: keys",
+				"are sorted and written as basic strings, strings are written as basic strings, integers in decimal",
+				"and floats in their shortest form. Whole floats are written as integers, unless `strict_numbers`",
+				"is set. Offset date-times are converted to UTC, and fractional seconds are written without",
+				"trailing zeros. The encoding is stable across versions of the provider.",
+				"",
+				"An optional second argument is an object configuring the comparison, as for the `equal` function.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "input",
+				MarkdownDescription: "TOML content to fingerprint",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the comparison",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (r FingerprintFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var data string
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &data, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	var options tomlComparisonOptions
+	options, resp.Error = parseComparisonOptionsArgument(optionsArgs, 1)
+	if resp.Error != nil {
+		return
+	}
+
+	canonical, err := canonicalTomlDocument([]byte(data), options)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The TOML content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(data), "", err)),
+		)
+		return
+	}
+
+	checksum := sha256.Sum256([]byte(canonical))
+	resp.Error = resp.Result.Set(ctx, types.StringValue(hex.EncodeToString(checksum[:])))
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testFingerprintConfig = `
+output "test" {
+	value = provider::toml::fingerprint(<<EOF
+# The answer.
+b = 1.0
+a = "x"
+EOF
+	)
+}
+
+output "reordered" {
+	value = provider::toml::fingerprint("a = 'x'\nb = 1")
+}
+`
+
+	// The SHA-256 checksum of {"a"="x","b"=1}.
+	testFingerprintExpectedOutput = "cd078bad8fd786467e8bdee7c7d357c43ea0918cc8ec89d04ae5da3f38744b81"
+
+	testFingerprintInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::fingerprint("a = 1", { ordered = true })
+}
+`
+)
+
+func TestCanonicalTomlDocument(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		options  tomlComparisonOptions
+		expected string
+	}{
+		"tables": {
+			document: "b = 'x'\n[a]\nd = true\nc = [1, 2]",
+			expected: `{"a"={"c"=[1,2],"d"=true},"b"="x"}`,
+		},
+		"strings": {
+			document: `a = 'C:\path'` + "\n" + `"b c" = """line\n"""`,
+			expected: `{"a"="C:\\path","b c"="line\n"}`,
+		},
+		"numbers": {
+			document: "a = 1.0\nb = 0x10\nc = 1.5\nd = -0.0\ne = nan\nf = -inf\ng = 1e3",
+			expected: `{"a"=1,"b"=16,"c"=1.5,"d"=0,"e"=nan,"f"=-inf,"g"=1000}`,
+		},
+		"strict numbers": {
+			document: "a = 1.0\nb = 1\nc = -0.0",
+			options:  tomlComparisonOptions{strictNumbers: true},
+			expected: `{"a"=1.0,"b"=1,"c"=0.0}`,
+		},
+		"datetimes": {
+			document: "a = 1979-05-27T00:32:00.500-07:00\nb = 1979-05-27 07:32:00.100\nc = 1979-05-27\nd = 07:32:00.000",
+			expected: `{"a"=1979-05-27T07:32:00.5Z,"b"=1979-05-27T07:32:00.1,"c"=1979-05-27,"d"=07:32:00}`,
+		},
+		"ordered arrays": {
+			document: "a = [3, 1, 2]",
+			expected: `{"a"=[3,1,2]}`,
+		},
+		"unordered arrays": {
+			document: "a = [3, 1, [2, 1]]\n[[b]]\nc = 2\n[[b]]\nc = 1",
+			options:  tomlComparisonOptions{unorderedArrays: true},
+			expected: `{"a"=[1,3,[1,2]],"b"=[{"c"=1},{"c"=2}]}`,
+		},
+		"tagged values": {
+			document: "a = { toml_type = 'float', value = 'nan' }",
+			expected: `{"a"={"toml_type"="float","value"="nan"}}`,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := canonicalTomlDocument([]byte(testCase.document), testCase.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestFingerprintFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testFingerprintInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`unsupported\s+option\s+"ordered"`),
+			},
+			{
+				Config: testFingerprintConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.StringExact(testFingerprintExpectedOutput),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"reordered",
+						knownvalue.StringExact(testFingerprintExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
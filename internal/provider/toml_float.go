@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = FloatFunction{}
+)
+
+func NewFloatFunction() function.Function {
+	return FloatFunction{}
+}
+
+type FloatFunction struct{}
+
+func (r FloatFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "float"
+}
+
+func (r FloatFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Mark a number to be encoded as a TOML float",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns an object of the form `{ __toml_type = \"float\", value = ... }`, which the `encode` and `set`",
+				"functions write as a TOML float, even if the number is whole. For example, `2` is written as",
+				"`2.0` rather than `2`.",
+				"",
+				"Since Terraform numbers cannot be infinite or NaN, the strings `\"inf\"`, `\"-inf\"` and `\"nan\"` may",
+				"be given instead to write the special float values `inf`, `-inf` and `nan`. The `decode` function",
+				"returns these values in the same form.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "value",
+				MarkdownDescription: "Number to encode as a float, or one of `\"inf\"`, `\"-inf\"` or `\"nan\"`",
+			},
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (r FloatFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var dynamicArg types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &dynamicArg)
+
+	if resp.Error != nil {
+		return
+	}
+
+	var value attr.Value
+	switch underlyingValue := dynamicArg.UnderlyingValue().(type) {
+	case types.Number, types.Int64, types.Float64:
+		value = underlyingValue
+	case types.String:
+		if _, err := parseSpecialFloat(underlyingValue.ValueString()); err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("The value cannot be used as a float.\n\nOriginal Error: %s", err))
+			return
+		}
+		value = underlyingValue
+	default:
+		resp.Error = function.NewArgumentFuncError(0, "The value must be a number, or one of \"inf\", \"-inf\" or \"nan\"")
+		return
+	}
+
+	result, err := newTaggedValueObject(ctx, tomlTypeFloat, value, "")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("The float cannot be created.\n\nOriginal Error: %s", err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(result))
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const testFloatConfig = `
+output "tagged" {
+	value = provider::toml::float(2)
+}
+
+output "encoded" {
+	value = provider::toml::encode({
+		interval = provider::toml::float(10)
+		jitter   = provider::toml::float(0.5)
+		limit    = provider::toml::float("-inf")
+		count    = 2
+	})
+}
+`
+
+func TestFloatFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testFloatConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"tagged",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"__toml_type": knownvalue.StringExact("float"),
+							"value":       knownvalue.Int64Exact(2),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"encoded",
+						knownvalue.StringExact("count = 2\ninterval = 10.0\njitter = 0.5\nlimit = -inf\n"),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = FormatFunction{}
+)
+
+func NewFormatFunction() function.Function {
+	return FormatFunction{}
+}
+
+type FormatFunction struct{}
+
+func (r FormatFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format"
+}
+
+func (r FormatFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Format TOML content, preserving its comments",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Formats TOML content with consistent spacing, indentation and quoting. Unlike decoding the content",
+				"and encoding it again, the content is formatted from its syntax, so comments, the order of tables",
+				"and keys, and the grouping of key-values by blank lines are preserved. Consecutive blank lines are",
+				"collapsed into one, and the text of numbers, dates and times is kept as written.",
+				"",
+				"An optional second argument is an object configuring the formatting. It supports the following",
+				"attributes:",
+				"",
+				"| Attribute          | Description                                                                                    |",
+				"|--------------------|------------------------------------------------------------------------------------------------|",
+				"| `table_indent`     | The indentation added for each level of table nesting. Default `\"\"`.                           |",
+				"| `array_indent`     | The indentation of the elements of multiline arrays. Default two spaces.                       |",
+				"| `column_width`     | The width beyond which arrays are written on multiple lines. Default `80`.                     |",
+				"| `array_expansion`  | When to write each element of an array on its own line: `auto` (default), `always` or `never`. |",
+				"| `align_entries`    | Whether to align the `=` of the key-values of each group. Default `false`.                     |",
+				"| `reorder_keys`     | Whether to sort the key-values of each group, and the keys of inline tables. Default `false`.  |",
+				"| `quote_style`      | `preserve` (default) to keep strings as written, or `literal` or `basic` as for `encode`.      |",
+				"| `trailing_newline` | Whether to end the result with a newline. Default `true`.                                      |",
+				"",
+				"Groups of key-values are separated by blank lines and table headers. With `reorder_keys`, comments",
+				"on the lines directly preceding a key-value move along with it.",
+				"",
+				"With `array_expansion = \"auto\"`, arrays are written on one line unless that line would be wider",
+				"than `column_width`. Arrays containing comments are always written on multiple lines, and arrays",
+				"within inline tables on one line where possible. Multiline strings and the keys of key-values are",
+				"kept as written, apart from whitespace around the dots of dotted keys.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "input",
+				MarkdownDescription: "TOML content to format",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the formatting",
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (r FormatFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var data string
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &data, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	options := defaultTomlFormatterOptions()
+	if len(optionsArgs) > 1 {
+		resp.Error = function.NewArgumentFuncError(
+			2,
+			"At most one options argument may be given",
+		)
+		return
+	}
+	if len(optionsArgs) == 1 {
+		optionsValue, err := convertFromTerraformType(optionsArgs[0].UnderlyingValue())
+		if err == nil {
+			options, err = parseTomlFormatterOptions(optionsValue)
+		}
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(
+				1,
+				fmt.Sprintf("The format options are invalid.\n\nOriginal Error: %s", err),
+			)
+			return
+		}
+	}
+
+	formatted, err := formatToml([]byte(data), options)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The TOML content cannot be formatted.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(data), "", err)),
+		)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.StringValue(string(formatted)))
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testFormatConfig = `
+locals {
+	document = <<EOF
+# Package metadata.
+[ package ]
+version="0.1.0"   # The version.
+name = 'example'
+keywords = [ "toml",
+  "terraform" ]
+EOF
+}
+
+output "default" {
+	value = provider::toml::format(local.document)
+}
+
+output "options" {
+	value = provider::toml::format(local.document, {
+		align_entries   = true
+		reorder_keys    = true
+		array_expansion = "always"
+		quote_style     = "basic"
+	})
+}
+`
+
+	testFormatDefaultExpectedOutput = `# Package metadata.
+[package]
+version = "0.1.0" # The version.
+name = 'example'
+keywords = ["toml", "terraform"]
+`
+
+	testFormatOptionsExpectedOutput = `# Package metadata.
+[package]
+keywords = [
+  "toml",
+  "terraform"
+]
+name     = "example"
+version  = "0.1.0" # The version.
+`
+
+	testFormatInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::format("a = 1", { array_expansion = "sometimes" })
+}
+`
+)
+
+func TestFormatFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testFormatInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`array_expansion\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config: testFormatConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"default",
+						knownvalue.StringExact(testFormatDefaultExpectedOutput),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"options",
+						knownvalue.StringExact(testFormatOptionsExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
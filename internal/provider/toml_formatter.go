@@ -0,0 +1,616 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// tomlFormatterOptions control how TOML content is formatted.
+type tomlFormatterOptions struct {
+	// tableIndent is added to the indentation for each level of table nesting.
+	tableIndent string
+	// arrayIndent is added to the indentation of the elements of multiline arrays.
+	arrayIndent string
+	// columnWidth is the width beyond which arrays are expanded with the
+	// "auto" array expansion.
+	columnWidth int
+	// alignEntries aligns the equals signs of consecutive key-values.
+	alignEntries bool
+	// reorderKeys sorts the key-values within each group of a table, and the
+	// keys of inline tables.
+	reorderKeys bool
+	// arrayExpansion is when to write each element of an array on its own
+	// line: "auto", "always" or "never". Arrays containing comments are
+	// always expanded.
+	arrayExpansion string
+	// quoteStyle is how to quote single-line strings: "preserve", "literal"
+	// or "basic".
+	quoteStyle      string
+	trailingNewline bool
+}
+
+func defaultTomlFormatterOptions() tomlFormatterOptions {
+	return tomlFormatterOptions{
+		arrayIndent:     "  ",
+		columnWidth:     80,
+		arrayExpansion:  "auto",
+		quoteStyle:      "preserve",
+		trailingNewline: true,
+	}
+}
+
+// parseTomlFormatterOptions parses the options argument of the format
+// function, as returned by convertFromTerraformType.
+func parseTomlFormatterOptions(options any) (tomlFormatterOptions, error) {
+	result := defaultTomlFormatterOptions()
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return result, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "table_indent":
+			result.tableIndent, ok = value.(string)
+		case "array_indent":
+			result.arrayIndent, ok = value.(string)
+		case "column_width":
+			var width int64
+			width, ok = value.(int64)
+			if ok && width <= 0 {
+				return result, fmt.Errorf("column_width must be positive, got: %d", width)
+			}
+			result.columnWidth = int(width)
+		case "align_entries":
+			result.alignEntries, ok = value.(bool)
+		case "reorder_keys":
+			result.reorderKeys, ok = value.(bool)
+		case "array_expansion":
+			result.arrayExpansion, ok = value.(string)
+			if ok && result.arrayExpansion != "auto" && result.arrayExpansion != "always" && result.arrayExpansion != "never" {
+				return result, fmt.Errorf("array_expansion must be one of \"auto\", \"always\" or \"never\", got: %q", result.arrayExpansion)
+			}
+		case "quote_style":
+			result.quoteStyle, ok = value.(string)
+			if ok && result.quoteStyle != "preserve" && result.quoteStyle != "literal" && result.quoteStyle != "basic" {
+				return result, fmt.Errorf("quote_style must be one of \"preserve\", \"literal\" or \"basic\", got: %q", result.quoteStyle)
+			}
+		case "trailing_newline":
+			result.trailingNewline, ok = value.(bool)
+		default:
+			return result, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return result, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+	return result, nil
+}
+
+// tomlFormatItem is a line of a TOML document being formatted: a blank line,
+// a comment, a table header or a key-value.
+type tomlFormatItem struct {
+	kind tomlFormatItemKind
+	// text is the comment, or the normalized key of a header or key-value.
+	text string
+	// depth is the number of keys of a header.
+	depth int
+	array bool
+	// sortKey is the key of a key-value used to sort it.
+	sortKey string
+	value   *tomlValueNode
+	// comment is the comment following a header or key-value on its line.
+	comment string
+}
+
+type tomlFormatItemKind int
+
+const (
+	tomlFormatBlank tomlFormatItemKind = iota
+	tomlFormatComment
+	tomlFormatHeader
+	tomlFormatKeyValue
+)
+
+// tomlFormatter formats TOML content from its syntax tree, so that comments
+// and the grouping of key-values by blank lines are preserved.
+type tomlFormatter struct {
+	doc     *tomlDocument
+	options tomlFormatterOptions
+	b       []byte
+}
+
+// formatToml formats TOML content using the given options.
+func formatToml(data []byte, options tomlFormatterOptions) ([]byte, error) {
+	// The parser only checks the syntax of the document, so decode it first to
+	// report any other errors, such as duplicate keys.
+	var decoded any
+	if err := toml.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	f := &tomlFormatter{doc: &tomlDocument{data: data}, options: options}
+	items, err := f.parseItems()
+	if err != nil {
+		return nil, err
+	}
+	if options.reorderKeys {
+		items = reorderTomlFormatItems(items)
+	}
+	f.writeItems(items)
+
+	f.b = bytes.TrimRight(f.b, "\n")
+	if options.trailingNewline && len(f.b) > 0 {
+		f.b = append(f.b, '\n')
+	}
+
+	// Formatting must never change the content, so check it as a safeguard.
+	var formatted any
+	if err := toml.Unmarshal(f.b, &formatted); err != nil {
+		return nil, fmt.Errorf("formatting the document would make it invalid: %w", err)
+	}
+	if !reflect.DeepEqual(tagSpecialFloats(decoded), tagSpecialFloats(formatted)) {
+		return nil, fmt.Errorf("formatting the document would change its content")
+	}
+	return f.b, nil
+}
+
+// parseItems splits the document into the items to format.
+func (f *tomlFormatter) parseItems() ([]tomlFormatItem, error) {
+	d := f.doc
+	var items []tomlFormatItem
+	arrayTables := make(map[string]int)
+	pos := 0
+
+	p := unstable.Parser{}
+	p.Reset(d.data)
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			section, err := d.parseHeader(expr, arrayTables)
+			if err != nil {
+				return nil, err
+			}
+			items = appendTomlFillerItems(items, d.data[pos:section.headerStart])
+
+			open := d.skipWhitespace(section.headerStart)
+			brackets := 1
+			if section.array {
+				brackets = 2
+			}
+			header := section.header[brackets : len(section.header)-brackets]
+			items = append(items, tomlFormatItem{
+				kind:    tomlFormatHeader,
+				text:    normalizeTomlKey(header),
+				depth:   len(section.path.keys()),
+				array:   section.array,
+				comment: trailingTomlComment(d.data[open+len(section.header) : section.headerEnd]),
+			})
+			pos = section.headerEnd
+		case unstable.KeyValue:
+			key, keyStart, valueStart, err := d.parseKey(expr)
+			if err != nil {
+				return nil, err
+			}
+			keyValue, err := d.parseKeyValue(expr)
+			if err != nil {
+				return nil, err
+			}
+			items = appendTomlFillerItems(items, d.data[pos:keyValue.start])
+			items = append(items, tomlFormatItem{
+				kind:    tomlFormatKeyValue,
+				text:    normalizeTomlKey(d.keyText(keyStart, valueStart)),
+				sortKey: key.String(),
+				value:   keyValue.value,
+				comment: trailingTomlComment(d.data[keyValue.value.end:keyValue.end]),
+			})
+			pos = keyValue.end
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, err
+	}
+	return appendTomlFillerItems(items, d.data[pos:]), nil
+}
+
+// keyText returns the text of a key, given the offset of the key and of its value.
+func (d *tomlDocument) keyText(keyStart, valueStart int) string {
+	end := valueStart - 1
+	for end > keyStart && d.data[end] != '=' {
+		end--
+	}
+	return strings.TrimRight(string(d.data[keyStart:end]), " \t")
+}
+
+// appendTomlFillerItems appends the blank lines and comments of the filler
+// between expressions. Consecutive blank lines are collapsed into one.
+func appendTomlFillerItems(items []tomlFormatItem, filler []byte) []tomlFormatItem {
+	lines := strings.Split(string(filler), "\n")
+	// The filler ends at the start of a line, which is not a blank line.
+	if strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "#"):
+			items = append(items, tomlFormatItem{kind: tomlFormatComment, text: line})
+		case line == "" && len(items) > 0 && items[len(items)-1].kind != tomlFormatBlank:
+			items = append(items, tomlFormatItem{kind: tomlFormatBlank})
+		}
+	}
+	return items
+}
+
+// trailingTomlComment returns the comment within the rest of a line, if any.
+func trailingTomlComment(rest []byte) string {
+	return strings.TrimSpace(string(rest))
+}
+
+// normalizeTomlKey removes the whitespace around the dots of a possibly
+// dotted key, keeping the quoting of each of its keys.
+func normalizeTomlKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case ' ', '\t':
+		case '"', '\'':
+			end := i + 1
+			for end < len(key) && key[end] != c {
+				if c == '"' && key[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			b.WriteString(key[i:min(end+1, len(key))])
+			i = end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// reorderTomlFormatItems sorts the key-values within each group of a table,
+// where groups are separated by blank lines. Comments directly preceding a
+// key-value move along with it.
+func reorderTomlFormatItems(items []tomlFormatItem) []tomlFormatItem {
+	result := make([]tomlFormatItem, 0, len(items))
+	var units [][]tomlFormatItem
+	var pending []tomlFormatItem
+	flush := func() {
+		sort.SliceStable(units, func(i, j int) bool {
+			return units[i][len(units[i])-1].sortKey < units[j][len(units[j])-1].sortKey
+		})
+		for _, unit := range units {
+			result = append(result, unit...)
+		}
+		result = append(result, pending...)
+		units, pending = nil, nil
+	}
+
+	for _, item := range items {
+		switch item.kind {
+		case tomlFormatComment:
+			pending = append(pending, item)
+		case tomlFormatKeyValue:
+			units = append(units, append(pending, item))
+			pending = nil
+		default:
+			flush()
+			result = append(result, item)
+		}
+	}
+	flush()
+	return result
+}
+
+// writeItems writes the formatted items.
+func (f *tomlFormatter) writeItems(items []tomlFormatItem) {
+	depth := 0
+	keyWidth := f.groupKeyWidth(items)
+	for i, item := range items {
+		switch item.kind {
+		case tomlFormatBlank:
+			if len(f.b) > 0 {
+				f.b = append(f.b, '\n')
+			}
+			keyWidth = f.groupKeyWidth(items[i+1:])
+		case tomlFormatComment:
+			// Comments directly preceding a header are indented like it.
+			level := depth
+			for _, next := range items[i+1:] {
+				if next.kind != tomlFormatComment {
+					if next.kind == tomlFormatHeader {
+						level = next.depth - 1
+					}
+					break
+				}
+			}
+			f.indent(level)
+			f.b = append(f.b, item.text...)
+			f.b = append(f.b, '\n')
+		case tomlFormatHeader:
+			depth = item.depth
+			f.indent(depth - 1)
+			if item.array {
+				f.b = append(f.b, "[["...)
+			} else {
+				f.b = append(f.b, '[')
+			}
+			f.b = append(f.b, item.text...)
+			if item.array {
+				f.b = append(f.b, "]]"...)
+			} else {
+				f.b = append(f.b, ']')
+			}
+			f.writeComment(item.comment)
+			f.b = append(f.b, '\n')
+			keyWidth = f.groupKeyWidth(items[i+1:])
+		case tomlFormatKeyValue:
+			f.indent(depth)
+			f.b = append(f.b, item.text...)
+			if f.options.alignEntries {
+				f.b = append(f.b, strings.Repeat(" ", keyWidth-utf8.RuneCountInString(item.text))...)
+			}
+			f.b = append(f.b, " = "...)
+			f.writeValue(item.value, f.lineIndentation())
+			f.writeComment(item.comment)
+			f.b = append(f.b, '\n')
+		}
+	}
+}
+
+// groupKeyWidth returns the width of the longest key of the group of
+// key-values starting with the given items, which ends at the next blank line
+// or header.
+func (f *tomlFormatter) groupKeyWidth(items []tomlFormatItem) int {
+	width := 0
+	for _, item := range items {
+		if item.kind != tomlFormatKeyValue && item.kind != tomlFormatComment {
+			break
+		}
+		if item.kind == tomlFormatKeyValue {
+			width = max(width, utf8.RuneCountInString(item.text))
+		}
+	}
+	return width
+}
+
+func (f *tomlFormatter) writeComment(comment string) {
+	if comment != "" {
+		f.b = append(f.b, ' ')
+		f.b = append(f.b, comment...)
+	}
+}
+
+func (f *tomlFormatter) indent(level int) {
+	for i := 0; i < level; i++ {
+		f.b = append(f.b, f.options.tableIndent...)
+	}
+}
+
+// lineIndentation returns the indentation of the line being written.
+func (f *tomlFormatter) lineIndentation() string {
+	line := f.b[bytes.LastIndexByte(f.b, '\n')+1:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// column returns the width of the line being written.
+func (f *tomlFormatter) column() int {
+	return utf8.RuneCount(f.b[bytes.LastIndexByte(f.b, '\n')+1:])
+}
+
+// tomlArrayComments are the comments within an array.
+type tomlArrayComments struct {
+	// before are the comments on their own lines preceding each element, and
+	// trailing the comments following each element on its line.
+	before   [][]string
+	trailing []string
+	// last are the comments following the last element on their own lines.
+	last []string
+}
+
+func (c tomlArrayComments) empty() bool {
+	if len(c.last) > 0 {
+		return false
+	}
+	for i := range c.before {
+		if len(c.before[i]) > 0 || c.trailing[i] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayComments finds the comments between the elements of an array.
+func (f *tomlFormatter) arrayComments(node *tomlValueNode) tomlArrayComments {
+	comments := tomlArrayComments{
+		before:   make([][]string, len(node.entries)),
+		trailing: make([]string, len(node.entries)),
+	}
+
+	gapStart := node.start + 1
+	for i := 0; i <= len(node.entries); i++ {
+		gapEnd := node.end - 1
+		if i < len(node.entries) {
+			gapEnd = node.entries[i].start
+		}
+
+		sawNewline := i == 0
+		gap := f.doc.data[gapStart:gapEnd]
+		for pos := 0; pos < len(gap); pos++ {
+			switch gap[pos] {
+			case '\n':
+				sawNewline = true
+			case '#':
+				end := bytes.IndexByte(gap[pos:], '\n')
+				if end < 0 {
+					end = len(gap) - pos
+				}
+				comment := strings.TrimSpace(string(gap[pos : pos+end]))
+				switch {
+				case !sawNewline:
+					comments.trailing[i-1] = comment
+				case i < len(node.entries):
+					comments.before[i] = append(comments.before[i], comment)
+				default:
+					comments.last = append(comments.last, comment)
+				}
+				pos += end - 1
+			}
+		}
+
+		if i < len(node.entries) {
+			gapStart = node.entries[i].value.end
+		}
+	}
+	return comments
+}
+
+// inlineValue returns a value formatted on a single line, or false if it
+// contains comments and so cannot be.
+func (f *tomlFormatter) inlineValue(node *tomlValueNode) (string, bool) {
+	switch node.kind {
+	case unstable.Array:
+		if !f.arrayComments(node).empty() {
+			return "", false
+		}
+		elements := make([]string, len(node.entries))
+		for i, entry := range node.entries {
+			element, ok := f.inlineValue(entry.value)
+			if !ok {
+				return "", false
+			}
+			elements[i] = element
+		}
+		return "[" + strings.Join(elements, ", ") + "]", true
+	case unstable.InlineTable:
+		var b strings.Builder
+		b.WriteByte('{')
+		for i, entry := range f.inlineTableEntries(node) {
+			value, ok := f.inlineValue(entry.value)
+			if !ok {
+				return "", false
+			}
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(normalizeTomlKey(f.doc.keyText(entry.start, entry.value.start)))
+			b.WriteString(" = ")
+			b.WriteString(value)
+		}
+		b.WriteByte('}')
+		return b.String(), true
+	case unstable.String:
+		return f.formatString(string(f.doc.data[node.start:node.end])), true
+	default:
+		return string(f.doc.data[node.start:node.end]), true
+	}
+}
+
+// inlineTableEntries returns the entries of an inline table in the order to
+// write them.
+func (f *tomlFormatter) inlineTableEntries(node *tomlValueNode) []*tomlValueEntry {
+	if !f.options.reorderKeys {
+		return node.entries
+	}
+	entries := append([]*tomlValueEntry(nil), node.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key.String() < entries[j].key.String()
+	})
+	return entries
+}
+
+// writeValue writes a value starting on a line with the given indentation,
+// expanding arrays onto multiple lines as configured.
+func (f *tomlFormatter) writeValue(node *tomlValueNode, indentation string) {
+	text, ok := f.inlineValue(node)
+	if ok {
+		expand := false
+		if node.kind == unstable.Array && len(node.entries) > 0 {
+			switch f.options.arrayExpansion {
+			case "always":
+				expand = true
+			case "auto":
+				expand = f.column()+utf8.RuneCountInString(text) > f.options.columnWidth
+			}
+		}
+		if !expand {
+			f.b = append(f.b, text...)
+			return
+		}
+	}
+
+	if node.kind == unstable.InlineTable {
+		// Inline tables are written on a single line, except for any arrays
+		// within them which contain comments.
+		f.b = append(f.b, '{')
+		for i, entry := range f.inlineTableEntries(node) {
+			if i > 0 {
+				f.b = append(f.b, ", "...)
+			}
+			f.b = append(f.b, normalizeTomlKey(f.doc.keyText(entry.start, entry.value.start))...)
+			f.b = append(f.b, " = "...)
+			f.writeValue(entry.value, indentation)
+		}
+		f.b = append(f.b, '}')
+		return
+	}
+
+	comments := f.arrayComments(node)
+	elementIndentation := indentation + f.options.arrayIndent
+	writeCommentLines := func(lines []string) {
+		for _, line := range lines {
+			f.b = append(f.b, '\n')
+			f.b = append(f.b, elementIndentation...)
+			f.b = append(f.b, line...)
+		}
+	}
+
+	f.b = append(f.b, '[')
+	for i, entry := range node.entries {
+		writeCommentLines(comments.before[i])
+		f.b = append(f.b, '\n')
+		f.b = append(f.b, elementIndentation...)
+		f.writeValue(entry.value, elementIndentation)
+		if i < len(node.entries)-1 {
+			f.b = append(f.b, ',')
+		}
+		f.writeComment(comments.trailing[i])
+	}
+	writeCommentLines(comments.last)
+	f.b = append(f.b, '\n')
+	f.b = append(f.b, indentation...)
+	f.b = append(f.b, ']')
+}
+
+// formatString formats a string value according to the quote style. Multiline
+// strings are written as they are.
+func (f *tomlFormatter) formatString(raw string) string {
+	if f.options.quoteStyle == "preserve" || strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''") {
+		return raw
+	}
+
+	var decoded map[string]string
+	if err := toml.Unmarshal([]byte("v = "+raw), &decoded); err != nil {
+		return raw
+	}
+	e := &tomlEncoder{options: tomlEncoderOptions{basicStrings: f.options.quoteStyle == "basic"}}
+	e.encodeString(decoded["v"])
+	return string(e.b)
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"testing"
+)
+
+const testFormatDocument = `
+
+# Package metadata.
+name="example"   # The name.
+tags   =   [ "a",'b' ]
+owner . email = 'owner@example.com'
+
+
+[ dependencies ]
+serde={ version="1.0",features=[ "derive" ] }
+anyhow   = "1.0"
+# Binaries.
+[[ bin ]]
+name = "first"
+paths = [
+    "src/main.rs", # The entry point.
+    # Generated code.
+    "src/gen.rs"
+]
+`
+
+func TestFormatToml(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		options  map[string]any
+		expected string
+		err      bool
+	}{
+		"defaults": {
+			document: testFormatDocument,
+			expected: `# Package metadata.
+name = "example" # The name.
+tags = ["a", 'b']
+owner.email = 'owner@example.com'
+
+[dependencies]
+serde = {version = "1.0", features = ["derive"]}
+anyhow = "1.0"
+# Binaries.
+[[bin]]
+name = "first"
+paths = [
+  "src/main.rs", # The entry point.
+  # Generated code.
+  "src/gen.rs"
+]
+`,
+		},
+		"align and reorder": {
+			document: testFormatDocument,
+			options: map[string]any{
+				"align_entries": true,
+				"reorder_keys":  true,
+				"quote_style":   "basic",
+			},
+			expected: `# Package metadata.
+name        = "example" # The name.
+owner.email = "owner@example.com"
+tags        = ["a", "b"]
+
+[dependencies]
+anyhow = "1.0"
+serde  = {features = ["derive"], version = "1.0"}
+# Binaries.
+[[bin]]
+name  = "first"
+paths = [
+  "src/main.rs", # The entry point.
+  # Generated code.
+  "src/gen.rs"
+]
+`,
+		},
+		"always expand arrays": {
+			document: "a = [1, [2, 3], []]\nb = {c = [4]}\n",
+			options:  map[string]any{"array_expansion": "always", "array_indent": "    "},
+			expected: "a = [\n    1,\n    [\n        2,\n        3\n    ],\n    []\n]\nb = {c = [4]}\n",
+		},
+		"never expand arrays": {
+			document: "a = [\n  1,\n  2,\n]\nb = [\n  3, # Three.\n]\n",
+			options:  map[string]any{"array_expansion": "never"},
+			expected: "a = [1, 2]\nb = [\n  3 # Three.\n]\n",
+		},
+		"column width": {
+			document: "short = [1, 2]\nlong = ['alpha', 'beta', 'gamma']\n",
+			options:  map[string]any{"column_width": int64(20)},
+			expected: "short = [1, 2]\nlong = [\n  'alpha',\n  'beta',\n  'gamma'\n]\n",
+		},
+		"table indentation": {
+			document: "[a]\nb = 1\n# About c.\n[a.c]\nd = [2]\n\n[[a.c.e]]\nf = 3\n",
+			options:  map[string]any{"table_indent": "  ", "array_expansion": "always"},
+			expected: "[a]\n  b = 1\n  # About c.\n  [a.c]\n    d = [\n      2\n    ]\n\n    [[a.c.e]]\n      f = 3\n",
+		},
+		"literal strings": {
+			document: "a = \"plain\"\nb = \"it's\"\nc = \"\"\"\nmultiline\"\"\"\n\"quoted key\" = \"tab\\t\"\n",
+			options:  map[string]any{"quote_style": "literal", "trailing_newline": false},
+			expected: "a = 'plain'\nb = \"it's\"\nc = \"\"\"\nmultiline\"\"\"\n\"quoted key\" = 'tab\t'",
+		},
+		"special values": {
+			document: "a=nan\nb = 1979-05-27T07:32:00Z\nc=0xdead_beef\n",
+			expected: "a = nan\nb = 1979-05-27T07:32:00Z\nc = 0xdead_beef\n",
+		},
+		"comments only": {
+			document: "\n\n# Nothing here.\n\n\n# Really.\n\n",
+			expected: "# Nothing here.\n\n# Really.\n",
+		},
+		"invalid document": {
+			document: "a = 1\na = 2\n",
+			err:      true,
+		},
+		"invalid options": {
+			document: "a = 1\n",
+			options:  map[string]any{"array_expansion": "sometimes"},
+			err:      true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			options := map[string]any{}
+			if testCase.options != nil {
+				options = testCase.options
+			}
+			formatterOptions, err := parseTomlFormatterOptions(options)
+			var actual []byte
+			if err == nil {
+				actual, err = formatToml([]byte(testCase.document), formatterOptions)
+			}
+			if testCase.err {
+				if err == nil {
+					t.Fatalf("expected error, got:\n%s", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != testCase.expected {
+				t.Errorf("unexpected result:\n%s\nexpected:\n%s", actual, testCase.expected)
+			}
+		})
+	}
+}
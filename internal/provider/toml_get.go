@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	_ function.Function = GetFunction{}
+)
+
+func NewGetFunction() function.Function {
+	return GetFunction{}
+}
+
+type GetFunction struct{}
+
+func (r GetFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "get"
+}
+
+func (r GetFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Get the value at a path within a TOML document",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns the value at the given path within a TOML document. Values are converted in the same way",
+				"as the `decode` function.",
+				"",
+				"The path uses TOML dotted key syntax, with array indexes in brackets, e.g. `package.version`,",
+				"`servers.\"alpha.example.com\".ip` or `bin[0].name`. An empty path refers to the whole document.",
+				"",
+				"If the path does not exist, the default value is returned if one is given, otherwise an error",
+				"is raised.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "document",
+				MarkdownDescription: "TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path of the value within the document",
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "default",
+			MarkdownDescription: "Optional value to return if the path does not exist",
+			AllowNullValue:      true,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (r GetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document types.Dynamic
+	var pathString string
+	var defaults []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &document, &pathString, &defaults)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if len(defaults) > 1 {
+		resp.Error = function.NewArgumentFuncError(2, "At most one default value can be given")
+		return
+	}
+
+	// A document with unknown values, e.g. when planning, cannot be searched,
+	// so the result is unknown until every nested value is known.
+	if !isFullyKnown(ctx, document) {
+		resp.Error = resp.Result.Set(ctx, types.DynamicUnknown())
+		return
+	}
+
+	decodedContent, keyPath, funcErr := decodeDocumentAndPath(document, pathString)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	value, ok := lookupDecodedValue(decodedContent, keyPath)
+	if !ok {
+		if len(defaults) == 0 {
+			resp.Error = function.NewArgumentFuncError(
+				1,
+				fmt.Sprintf("The path %q does not exist in the TOML document", pathString),
+			)
+			return
+		}
+		resp.Error = resp.Result.Set(ctx, defaults[0])
+		return
+	}
+
+	if value == nil {
+		resp.Error = resp.Result.Set(ctx, types.DynamicNull())
+		return
+	}
+
+	_, terraformValue, diags := convertToTerraformType(value)
+
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(terraformValue))
+}
+
+// decodeDocumentAndPath decodes the document and path arguments shared by the
+// path functions. An empty path refers to the whole document.
+func decodeDocumentAndPath(document types.Dynamic, pathString string) (any, tomlPath, *function.FuncError) {
+	decodedContent, funcErr := decodeDocumentArgument(document, 0)
+	if funcErr != nil {
+		return nil, nil, funcErr
+	}
+
+	if strings.TrimSpace(pathString) == "" {
+		return decodedContent, nil, nil
+	}
+
+	keyPath, err := parseTomlPath(pathString)
+	if err != nil {
+		return nil, nil, function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The path is invalid.\n\nOriginal Error: %s", err),
+		)
+	}
+	return decodedContent, keyPath, nil
+}
+
+// decodeDocumentArgument decodes a document argument, which is either TOML
+// content as a string, or a value which has already been decoded.
+func decodeDocumentArgument(document types.Dynamic, argument int64) (any, *function.FuncError) {
+	content, ok := document.UnderlyingValue().(types.String)
+	if !ok {
+		decodedContent, err := convertFromTerraformType(document.UnderlyingValue())
+		if err != nil {
+			return nil, function.NewArgumentFuncError(
+				argument,
+				fmt.Sprintf("The document cannot be converted.\n\nOriginal Error: %s", err),
+			)
+		}
+		return decodedContent, nil
+	}
+
+	var decodedContent any
+	if err := toml.Unmarshal([]byte(content.ValueString()), &decodedContent); err != nil {
+		return nil, function.NewArgumentFuncError(
+			argument,
+			fmt.Sprintf("The TOML document cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(content.ValueString()), "", err)),
+		)
+	}
+	return decodedContent, nil
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testPathFunctionDocument = `
+locals {
+	document = <<EOF
+[package]
+name = "example"
+version = "0.1.0"
+
+[servers."alpha.example.com"]
+ip = "10.0.0.1"
+
+[[bin]]
+name = "first"
+port = 8080
+
+[[bin]]
+name = "second"
+EOF
+}
+`
+
+	testGetConfig = testPathFunctionDocument + `
+output "quoted_key" {
+	value = provider::toml::get(local.document, "servers.\"alpha.example.com\".ip")
+}
+
+output "array_index" {
+	value = provider::toml::get(local.document, "bin[0].port")
+}
+
+output "decoded" {
+	value = provider::toml::get(provider::toml::decode(local.document), "bin[1]")
+}
+
+output "default" {
+	value = provider::toml::get(local.document, "package.edition", "2021")
+}
+
+output "null_default" {
+	value = provider::toml::get(local.document, "package.edition", null) == null
+}
+
+output "null" {
+	value = provider::toml::get({ a = null, b = "x" }, "")
+}
+`
+
+	testGetUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "test" {
+	value = provider::toml::get({ a = { b = terraform_data.computed.output }, c = 1 }, "a.b")
+}
+`
+
+	testGetMissingConfig = testPathFunctionDocument + `
+output "test" {
+	value = provider::toml::get(local.document, "package.edition")
+}
+`
+)
+
+func TestGetFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testGetMissingConfig,
+				ExpectError: regexp.MustCompile(`The path "package.edition" does\s+not\s+exist\s+in\s+the\s+TOML\s+document`),
+			},
+			{
+				Config: testGetConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("quoted_key", knownvalue.StringExact("10.0.0.1")),
+					statecheck.ExpectKnownOutputValue("array_index", knownvalue.Int64Exact(8080)),
+					statecheck.ExpectKnownOutputValue(
+						"decoded",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"name": knownvalue.StringExact("second"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue("default", knownvalue.StringExact("2021")),
+					statecheck.ExpectKnownOutputValue("null_default", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue(
+						"null",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"a": knownvalue.Null(),
+							"b": knownvalue.StringExact("x"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestGetFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testGetUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("test"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact("value")),
+				},
+			},
+		},
+	})
+}
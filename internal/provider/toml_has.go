@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = HasFunction{}
+)
+
+func NewHasFunction() function.Function {
+	return HasFunction{}
+}
+
+type HasFunction struct{}
+
+func (r HasFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "has"
+}
+
+func (r HasFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Check whether a path exists within a TOML document",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns whether the given path exists within a TOML document.",
+				"",
+				"The path uses the same syntax as the `get` function.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "document",
+				MarkdownDescription: "TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path to check for within the document",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (r HasFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document types.Dynamic
+	var pathString string
+
+	resp.Error = req.Arguments.Get(ctx, &document, &pathString)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !isFullyKnown(ctx, document) {
+		resp.Error = resp.Result.Set(ctx, types.BoolUnknown())
+		return
+	}
+
+	decodedContent, keyPath, funcErr := decodeDocumentAndPath(document, pathString)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	_, ok := lookupDecodedValue(decodedContent, keyPath)
+
+	resp.Error = resp.Result.Set(ctx, types.BoolValue(ok))
+}
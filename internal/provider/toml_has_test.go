@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const testHasConfig = testPathFunctionDocument + `
+output "present" {
+	value = provider::toml::has(local.document, "bin[1].name")
+}
+
+output "missing" {
+	value = provider::toml::has(local.document, "bin[1].port")
+}
+
+output "decoded" {
+	value = provider::toml::has(provider::toml::decode(local.document), "package.version")
+}
+`
+
+const testHasUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "test" {
+	value = provider::toml::has({ a = { b = terraform_data.computed.output } }, "a.b")
+}
+`
+
+func TestHasFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHasConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("present", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("missing", knownvalue.Bool(false)),
+					statecheck.ExpectKnownOutputValue("decoded", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
+
+func TestHasFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHasUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("test"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = IntegerFunction{}
+)
+
+func NewIntegerFunction() function.Function {
+	return IntegerFunction{}
+}
+
+type IntegerFunction struct{}
+
+func (r IntegerFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "integer"
+}
+
+func (r IntegerFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Mark a number to be encoded as a TOML integer in a given format",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns an object of the form `{ __toml_type = \"integer\", value = ..., format = \"...\" }`, which",
+				"the `encode` and `set` functions write as a TOML integer in the given format: `decimal`, `hex`",
+				"(e.g. `0xff`), `octal` (e.g. `0o377`) or `binary` (e.g. `0b11111111`).",
+				"",
+				"Only non-negative integers can be written in hexadecimal, octal or binary.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.NumberParameter{
+				Name:                "value",
+				MarkdownDescription: "Whole number to encode",
+			},
+			function.StringParameter{
+				Name:                "format",
+				MarkdownDescription: "Format of the integer: `decimal`, `hex`, `octal` or `binary`",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				tomlTypeAttribute:   types.StringType,
+				tomlValueAttribute:  types.NumberType,
+				tomlFormatAttribute: types.StringType,
+			},
+		},
+	}
+}
+
+func (r IntegerFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value types.Number
+	var format string
+
+	resp.Error = req.Arguments.Get(ctx, &value, &format)
+
+	if resp.Error != nil {
+		return
+	}
+
+	bigFloat := value.ValueBigFloat()
+	intValue, accuracy := bigFloat.Int64()
+	if !bigFloat.IsInt() || accuracy != big.Exact {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The value %s is not a whole number representable as a TOML integer", bigFloat.String()),
+		)
+		return
+	}
+
+	if _, err := formatTaggedInteger(intValue, format); err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The integer cannot be formatted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	// The format is always part of the result, even when left empty.
+	if format == "" {
+		format = "decimal"
+	}
+
+	result, err := newTaggedValueObject(ctx, tomlTypeInteger, value, format)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("The integer cannot be created.\n\nOriginal Error: %s", err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testIntegerConfig = `
+output "tagged" {
+	value = provider::toml::integer(255, "hex")
+}
+
+output "empty_format" {
+	value = provider::toml::integer(5, "")
+}
+
+output "encoded" {
+	value = provider::toml::encode({
+		hex     = provider::toml::integer(255, "hex")
+		octal   = provider::toml::integer(493, "octal")
+		binary  = provider::toml::integer(5, "binary")
+		decimal = provider::toml::integer(-1, "decimal")
+	})
+}
+`
+
+	testIntegerFractionConfig = `
+output "test" {
+	value = provider::toml::integer(1.5, "hex")
+}
+`
+
+	testIntegerNegativeConfig = `
+output "test" {
+	value = provider::toml::integer(-1, "hex")
+}
+`
+)
+
+func TestIntegerFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testIntegerFractionConfig,
+				ExpectError: regexp.MustCompile(`is\s+not\s+a\s+whole\s+number`),
+			},
+			{
+				Config:      testIntegerNegativeConfig,
+				ExpectError: regexp.MustCompile(`negative\s+integers\s+can\s+only\s+be\s+written\s+in\s+decimal`),
+			},
+			{
+				Config: testIntegerConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"tagged",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"__toml_type": knownvalue.StringExact("integer"),
+							"value":       knownvalue.Int64Exact(255),
+							"format":      knownvalue.StringExact("hex"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"empty_format",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"__toml_type": knownvalue.StringExact("integer"),
+							"value":       knownvalue.Int64Exact(5),
+							"format":      knownvalue.StringExact("decimal"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"encoded",
+						knownvalue.StringExact("binary = 0b101\ndecimal = -1\nhex = 0xff\noctal = 0o755\n"),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,483 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &TomlKeyResource{}
+	_ resource.ResourceWithConfigure   = &TomlKeyResource{}
+	_ resource.ResourceWithImportState = &TomlKeyResource{}
+	_ resource.ResourceWithModifyPlan  = &TomlKeyResource{}
+)
+
+// NewTomlKeyResource is a helper function to simplify the provider implementation.
+func NewTomlKeyResource() resource.Resource {
+	return &TomlKeyResource{}
+}
+
+// TomlKeyResource is the resource implementation.
+type TomlKeyResource struct {
+	providerData tomlProviderData
+}
+
+// Metadata returns the resource type name.
+func (r *TomlKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key"
+}
+
+// Schema defines the schema for the resource.
+func (r *TomlKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "The `toml_key` resource manages a single key within an existing TOML file. Only the value " +
+			"of the key is rewritten, so the comments, ordering and formatting of the rest of the file are preserved.",
+		Attributes: map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path to the TOML file, which must already exist. Relative paths are resolved " +
+					"against the provider's `base_dir`. Changing this forces a new resource to be created.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"absolute_path": schema.StringAttribute{
+				Description: "The absolute path of the file, resolved from `filename` and the provider's `base_dir`. " +
+					"Changing this, for example by changing `base_dir`, forces a new resource to be created.",
+				Computed: true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path of the key within the file, using TOML dotted key syntax, e.g. " +
+					"`package.version` or `servers.\"alpha.example.com\".ip`. Missing tables are created, and are " +
+					"removed along with the key once they are empty. Changing this forces a new resource to be created.",
+				Required: true,
+				Validators: []validator.String{
+					tomlPathValidator{},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.DynamicAttribute{
+				Description: "The value of the key. The value is encoded in the same way as the `encode` function, " +
+					"with tables nested within other tables written inline.",
+				Required: true,
+			},
+			"id": schema.StringAttribute{
+				Description: "The filename and path of the key, separated by `#`.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Configure stores the provider data for use when managing the key.
+func (r *TomlKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(tomlProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected resource configure type",
+			fmt.Sprintf("Expected tomlProviderData, got: %T.", req.ProviderData),
+		)
+		return
+	}
+
+	r.providerData = providerData
+}
+
+// Create sets the key in the file.
+func (r *TomlKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan TomlKeyResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createdTable, ok := r.set(&plan, "Create TOML key resource error", &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	if createdTable != nil {
+		// Recorded so that the table can be removed along with the key.
+		createdTableJSON, err := json.Marshal(createdTable.String())
+		if err != nil {
+			resp.Diagnostics.AddError("Create TOML key resource error", err.Error())
+			return
+		}
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, createdTablePrivateKey, createdTableJSON)...)
+	}
+
+	plan.ID = types.StringValue(plan.Filename.ValueString() + "#" + plan.Path.ValueString())
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the Terraform state with the value of the key in the file.
+func (r *TomlKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state TomlKeyResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := r.stateFilename(state)
+	keyPath, err := parseTomlPath(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Read TOML key resource error", err.Error())
+		return
+	}
+
+	content, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML key resource error",
+			fmt.Sprintf("The file %q cannot be read.\n\nOriginal Error: %s", filename, err),
+		)
+		return
+	}
+
+	if state.AbsolutePath.IsNull() {
+		// Only happens when importing, or when the state was written by an
+		// earlier version of the provider.
+		absolutePath, err := filepath.Abs(filename)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Read TOML key resource error",
+				fmt.Sprintf("The path of file %q cannot be resolved.\n\nOriginal Error: %s", filename, err),
+			)
+			return
+		}
+		state.AbsolutePath = types.StringValue(absolutePath)
+	}
+
+	var decodedContent any
+	if err := toml.Unmarshal(content, &decodedContent); err != nil {
+		resp.Diagnostics.AddError(
+			"Read TOML key resource error",
+			fmt.Sprintf("The file %q cannot be decoded.\n\nOriginal Error: %s", filename, describeTomlDecodeError(content, filename, err)),
+		)
+		return
+	}
+
+	value, ok := lookupDecodedValue(decodedContent, keyPath)
+	if !ok {
+		// The key has been removed outside of Terraform, so it must be set again.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	stateValue, err := convertFromTerraformType(state.Value)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("value"),
+			"Read TOML key resource error",
+			fmt.Sprintf("The value in the state cannot be converted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	if state.Value.IsNull() || !tomlValuesEqual(value, stateValue) {
+		// The key has been changed outside of Terraform (or is being imported).
+		_, tfValue, diags := convertToTerraformType(value)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Value = types.DynamicValue(tfValue)
+	}
+
+	state.ID = types.StringValue(state.Filename.ValueString() + "#" + state.Path.ValueString())
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update sets the new value of the key in the file.
+func (r *TomlKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan TomlKeyResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, ok := r.set(&plan, "Update TOML key resource error", &resp.Diagnostics); !ok {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the key from the file.
+func (r *TomlKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state TomlKeyResourceModelV0
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyPath, err := parseTomlPath(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Delete TOML key resource error", err.Error())
+		return
+	}
+
+	var createdTable tomlPath
+	createdTableJSON, diags := req.Private.GetKey(ctx, createdTablePrivateKey)
+	resp.Diagnostics.Append(diags...)
+	if createdTableJSON != nil {
+		var createdTableString string
+		if err := json.Unmarshal(createdTableJSON, &createdTableString); err == nil {
+			createdTable, _ = parseTomlPath(createdTableString)
+		}
+	}
+
+	filename := r.stateFilename(state)
+	err = editTomlFile(filename, func(doc *tomlDocument) ([]byte, error) {
+		content, _, err := doc.Delete(keyPath)
+		if err != nil || len(createdTable) == 0 || !keyPath.hasPrefix(createdTable) {
+			return content, err
+		}
+
+		// Remove the tables created to hold the key, as long as nothing else
+		// has been added to them since.
+		for i := len(keyPath) - 1; i >= len(createdTable); i-- {
+			doc, err = parseTomlDocument(content)
+			if err != nil {
+				return nil, err
+			}
+			value, _ := lookupDecodedValue(doc.decoded, keyPath[:i])
+			if table, ok := value.(map[string]any); !ok || len(table) > 0 {
+				break
+			}
+			content, _, err = doc.Delete(keyPath[:i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return content, nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError(
+			"Delete TOML key resource error",
+			fmt.Sprintf("The key %s cannot be removed from file %q.\n\nOriginal Error: %s", keyPath, filename, err),
+		)
+	}
+}
+
+// ModifyPlan plans the absolute path of the file, replacing the key when
+// it changes.
+func (r *TomlKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// The resource is being destroyed.
+		return
+	}
+
+	var plan TomlKeyResourceModelV0
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.Filename.IsUnknown() {
+		return
+	}
+
+	absolutePath, err := r.providerData.absolutePath(plan.Filename.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("filename"),
+			"Plan TOML key resource error",
+			fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+	plan.AbsolutePath = types.StringValue(absolutePath)
+
+	// A relative filename refers to another file when the base directory
+	// changes, in which case the key must be removed from the old file.
+	if !req.State.Raw.IsNull() {
+		var state TomlKeyResourceModelV0
+
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !state.AbsolutePath.IsNull() && !state.AbsolutePath.Equal(plan.AbsolutePath) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("absolute_path"))
+		}
+	}
+
+	diags = resp.Plan.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState imports an existing key, using the filename and path of the key
+// separated by `#` as the import identifier.
+func (r *TomlKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	filename, keyPath, ok := strings.Cut(req.ID, "#")
+	if !ok || filename == "" || keyPath == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import identifier",
+			fmt.Sprintf("Expected an import identifier of the form <filename>#<path>, got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("filename"), filename)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("path"), keyPath)...)
+}
+
+// set sets the planned value of the key in the file, and the absolute path of
+// the file in the plan. It returns the path of the outermost table created to
+// hold the key, if any.
+func (r *TomlKeyResource) set(plan *TomlKeyResourceModelV0, summary string, diags *diag.Diagnostics) (tomlPath, bool) {
+	keyPath, err := parseTomlPath(plan.Path.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("path"), summary, err.Error())
+		return nil, false
+	}
+
+	value, err := convertFromTerraformType(plan.Value)
+	if err != nil {
+		diags.AddAttributeError(path.Root("value"), summary, err.Error())
+		return nil, false
+	}
+	filename, err := r.providerData.absolutePath(plan.Filename.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("filename"),
+			summary,
+			fmt.Sprintf("The path of the file cannot be resolved.\n\nOriginal Error: %s", err),
+		)
+		return nil, false
+	}
+	plan.AbsolutePath = types.StringValue(filename)
+
+	var createdTable tomlPath
+	err = editTomlFile(filename, func(doc *tomlDocument) ([]byte, error) {
+		for i := 1; i < len(keyPath); i++ {
+			if _, ok := lookupDecodedValue(doc.decoded, keyPath[:i]); !ok {
+				createdTable = keyPath[:i]
+				break
+			}
+		}
+		return doc.Set(keyPath, value)
+	})
+	if err != nil {
+		diags.AddError(
+			summary,
+			fmt.Sprintf("The key %s cannot be set in file %q.\n\nOriginal Error: %s", keyPath, filename, err),
+		)
+		return nil, false
+	}
+	return createdTable, true
+}
+
+// tomlValuesEqual returns whether a value decoded from a file has the same
+// meaning as a value from the state once that is encoded, which writes tagged
+// values such as float(2) as native TOML values and leaves out null values.
+func tomlValuesEqual(decoded any, stateValue any) bool {
+	encoded, err := encodeToml(map[string]any{"value": stateValue}, defaultTomlEncoderOptions())
+	if err != nil {
+		return false
+	}
+	options := tomlComparisonOptions{strictNumbers: true}
+	canonicalStateValue, err := canonicalTomlDocument(encoded, options)
+	if err != nil {
+		return false
+	}
+	return canonicalStateValue == canonicalTomlValue(map[string]any{"value": decoded}, options)
+}
+
+// createdTablePrivateKey is the private state key recording the path of the
+// outermost table created to hold a key.
+const createdTablePrivateKey = "created_table"
+
+// tomlFileMutex serializes edits to TOML files, since several resources may
+// manage parts of the same file.
+var tomlFileMutex sync.Mutex
+
+// editTomlFile applies an edit to an existing TOML file, preserving its permissions.
+func editTomlFile(filename string, edit func(doc *tomlDocument) ([]byte, error)) error {
+	tomlFileMutex.Lock()
+	defer tomlFileMutex.Unlock()
+
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	doc, err := parseTomlDocument(content)
+	if err != nil {
+		return fmt.Errorf("%s", describeTomlDecodeError(content, filename, err))
+	}
+
+	content, err = edit(doc)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, content, fileInfo.Mode().Perm())
+}
+
+type TomlKeyResourceModelV0 struct {
+	Filename     types.String  `tfsdk:"filename"`
+	AbsolutePath types.String  `tfsdk:"absolute_path"`
+	Path         types.String  `tfsdk:"path"`
+	Value        types.Dynamic `tfsdk:"value"`
+	ID           types.String  `tfsdk:"id"`
+}
+
+// stateFilename returns the path of the file recorded in state, which is
+// where the key was written even if the base directory has since changed.
+func (r *TomlKeyResource) stateFilename(state TomlKeyResourceModelV0) string {
+	if !state.AbsolutePath.IsNull() && !state.AbsolutePath.IsUnknown() {
+		return state.AbsolutePath.ValueString()
+	}
+	return r.providerData.resolvePath(state.Filename.ValueString())
+}
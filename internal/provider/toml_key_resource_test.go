@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testAccTomlKeyResourceConfig = `
+resource "toml_key" "version" {
+  filename = %q
+  path     = "package.version"
+  value    = "0.2.0"
+}
+
+resource "toml_key" "feature" {
+  filename = %[1]q
+  path     = "features.\"serde-support\""
+  value    = ["serde"]
+}
+`
+
+	testAccTomlKeyResourceBaseDirConfig = `
+provider "toml" {
+  base_dir = %q
+}
+
+resource "toml_key" "version" {
+  filename = "Cargo.toml"
+  path     = "package.version"
+  value    = "0.2.0"
+}
+`
+
+	testAccTomlKeyResourceNullConfig = `
+resource "toml_key" "metadata" {
+  filename = %q
+  path     = "package.metadata"
+  value    = { a = 1, b = null }
+}
+`
+
+	testAccTomlKeyResourceTaggedConfig = `
+resource "toml_key" "float" {
+  filename = %q
+  path     = "package.float"
+  value    = provider::toml::float(2)
+}
+
+resource "toml_key" "metadata" {
+  filename = %[1]q
+  path     = "package.metadata"
+  value = {
+    integer = provider::toml::integer(255, "hex")
+    date    = provider::toml::local_date("2024-01-02")
+  }
+
+  depends_on = [toml_key.float]
+}
+`
+
+	testAccTomlKeyResourceInitialContent = `# Managed by hand, except for the version.
+[package]
+name = "example" # The crate name.
+version = "0.1.0"
+
+[dependencies]
+serde = "1.0"
+`
+
+	testAccTomlKeyResourceExpectedContent = `# Managed by hand, except for the version.
+[package]
+name = "example" # The crate name.
+version = "0.2.0"
+
+[dependencies]
+serde = "1.0"
+
+[features]
+serde-support = ['serde']
+`
+
+	testAccTomlKeyResourceDestroyedContent = `# Managed by hand, except for the version.
+[package]
+name = "example" # The crate name.
+
+[dependencies]
+serde = "1.0"
+`
+)
+
+func TestAccTomlKeyResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte(testAccTomlKeyResourceInitialContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(testAccTomlKeyResourceConfig, filename)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			return testAccCheckFileContent(filename, testAccTomlKeyResourceDestroyedContent)(nil)
+		},
+		Steps: []resource.TestStep{
+			// Create testing.
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, testAccTomlKeyResourceExpectedContent),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_key.version",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(filename+"#package.version"),
+					),
+				},
+			},
+			// Drift testing: the key is modified outside of Terraform.
+			{
+				PreConfig: func() {
+					content := []byte(testAccTomlKeyResourceExpectedContent[:len(testAccTomlKeyResourceExpectedContent)-len("['serde']\n")] + "[]\n")
+					if err := os.WriteFile(filename, content, 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testAccCheckFileContent(filename, testAccTomlKeyResourceExpectedContent),
+			},
+			// Import testing.
+			{
+				ResourceName:      "toml_key.version",
+				ImportState:       true,
+				ImportStateId:     filename + "#package.version",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccTomlKeyResource_baseDir(t *testing.T) {
+	oldBaseDir := t.TempDir()
+	newBaseDir := t.TempDir()
+	for _, baseDir := range []string{oldBaseDir, newBaseDir} {
+		if err := os.WriteFile(filepath.Join(baseDir, "Cargo.toml"), []byte("[package]\nname = \"example\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: func(_ *terraform.State) error {
+			return testAccCheckFileContent(filepath.Join(newBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n")(nil)
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccTomlKeyResourceBaseDirConfig, oldBaseDir),
+				Check:  testAccCheckFileContent(filepath.Join(oldBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\nversion = '0.2.0'\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"toml_key.version",
+						tfjsonpath.New("absolute_path"),
+						knownvalue.StringExact(filepath.Join(oldBaseDir, "Cargo.toml")),
+					),
+				},
+			},
+			// Changing the base directory moves the key to the other file.
+			{
+				Config: fmt.Sprintf(testAccTomlKeyResourceBaseDirConfig, newBaseDir),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("toml_key.version", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckFileContent(filepath.Join(oldBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\n"),
+					testAccCheckFileContent(filepath.Join(newBaseDir, "Cargo.toml"), "[package]\nname = \"example\"\nversion = '0.2.0'\n"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTomlKeyResource_null(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte("[package]\nname = \"example\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(testAccTomlKeyResourceNullConfig, filename)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, "[package]\nname = \"example\"\nmetadata = {a = 1}\n"),
+			},
+			// Null values are left out of the file, which is not drift.
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccTomlKeyResource_tagged(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "Cargo.toml")
+	if err := os.WriteFile(filename, []byte("[package]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(testAccTomlKeyResourceTaggedConfig, filename)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testAccCheckFileContent(filename, "[package]\nfloat = 2.0\nmetadata = {date = 2024-01-02, integer = 0xff}\n"),
+			},
+			// Tagged values are written as native TOML values, which is not drift.
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
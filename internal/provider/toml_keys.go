@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = KeysFunction{}
+)
+
+func NewKeysFunction() function.Function {
+	return KeysFunction{}
+}
+
+type KeysFunction struct{}
+
+func (r KeysFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "keys"
+}
+
+func (r KeysFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "List the keys of a table within a TOML document",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns the keys of the table at the given path within a TOML document, in lexicographical order.",
+				"",
+				"The path uses the same syntax as the `get` function. An error is raised if the path does not exist",
+				"or does not refer to a table.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "document",
+				MarkdownDescription: "TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path of the table within the document",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.StringType,
+		},
+	}
+}
+
+func (r KeysFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document types.Dynamic
+	var pathString string
+
+	resp.Error = req.Arguments.Get(ctx, &document, &pathString)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !isFullyKnown(ctx, document) {
+		resp.Error = resp.Result.Set(ctx, types.ListUnknown(types.StringType))
+		return
+	}
+
+	decodedContent, keyPath, funcErr := decodeDocumentAndPath(document, pathString)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	value, ok := lookupDecodedValue(decodedContent, keyPath)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The path %q does not exist in the TOML document", pathString),
+		)
+		return
+	}
+
+	table, ok := value.(map[string]any)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The value at path %q is not a table", pathString),
+		)
+		return
+	}
+
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resp.Error = resp.Result.Set(ctx, keys)
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testKeysConfig = testPathFunctionDocument + `
+output "root" {
+	value = provider::toml::keys(local.document, "")
+}
+
+output "table" {
+	value = provider::toml::keys(local.document, "package")
+}
+`
+
+	testKeysUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "test" {
+	value = provider::toml::keys({ a = { b = terraform_data.computed.output, c = 1 } }, "a")
+}
+`
+
+	testKeysNotTableConfig = testPathFunctionDocument + `
+output "test" {
+	value = provider::toml::keys(local.document, "bin")
+}
+`
+)
+
+func TestKeysFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testKeysNotTableConfig,
+				ExpectError: regexp.MustCompile(`The value at path "bin" is\s+not\s+a\s+table`),
+			},
+			{
+				Config: testKeysConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"root",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("bin"),
+							knownvalue.StringExact("package"),
+							knownvalue.StringExact("servers"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"table",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("name"),
+							knownvalue.StringExact("version"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestKeysFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testKeysUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("test"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("b"),
+							knownvalue.StringExact("c"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = MergeFunction{}
+)
+
+func NewMergeFunction() function.Function {
+	return MergeFunction{}
+}
+
+type MergeFunction struct{}
+
+func (r MergeFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge"
+}
+
+func (r MergeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Deep-merge TOML documents",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Deep-merges any number of TOML documents, returning a Terraform value in the same form as the",
+				"`decode` function. Each document may be TOML content as a string, or a value which has already",
+				"been decoded.",
+				"",
+				"Tables are merged recursively, and for any other value the value from the later document wins.",
+				"Arrays are replaced, and null values are ignored. Use the `merge_with` function to configure how",
+				"arrays are merged, or to raise an error when the types of two values conflict.",
+			},
+			"\n",
+		),
+		VariadicParameter: function.DynamicParameter{
+			Name:                "documents",
+			MarkdownDescription: "TOML content as strings, or values returned by the `decode` function",
+			AllowUnknownValues:  true,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (r MergeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var documents []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &documents)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !documentsFullyKnown(ctx, documents) {
+		resp.Error = resp.Result.Set(ctx, types.DynamicUnknown())
+		return
+	}
+
+	var result attr.Value
+	result, resp.Error = mergeDocuments(ctx, tomlMerger{arrays: "replace"}, documents, 0)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(result))
+}
+
+// documentsFullyKnown returns whether every document argument is known,
+// including any values nested within it. A merge of partially unknown
+// documents would be wrong, so the result is unknown until they all are.
+func documentsFullyKnown(ctx context.Context, documents []types.Dynamic) bool {
+	for _, document := range documents {
+		if !isFullyKnown(ctx, document) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeDocuments deep-merges the document arguments of the merge and
+// merge_with functions, the first of which is at the given position.
+func mergeDocuments(ctx context.Context, merger tomlMerger, documents []types.Dynamic, position int64) (attr.Value, *function.FuncError) {
+	var result any = map[string]any{}
+	for i, document := range documents {
+		decodedContent, funcErr := decodeDocumentArgument(document, position+int64(i))
+		if funcErr != nil {
+			return nil, funcErr
+		}
+
+		table, ok := normalizeDecodedValue(withoutNulls(decodedContent)).(map[string]any)
+		if !ok {
+			return nil, function.NewArgumentFuncError(
+				position+int64(i),
+				fmt.Sprintf("Document %d is not a table", i+1),
+			)
+		}
+
+		var err error
+		result, err = merger.merge(nil, result, table)
+		if err != nil {
+			return nil, function.NewArgumentFuncError(
+				position+int64(i),
+				fmt.Sprintf("Document %d cannot be merged.\n\nOriginal Error: %s", i+1, err),
+			)
+		}
+	}
+
+	_, terraformValue, diags := convertToTerraformType(result)
+
+	if diags.HasError() {
+		return nil, function.FuncErrorFromDiags(ctx, diags)
+	}
+	return terraformValue, nil
+}
+
+// tomlMerger deep-merges decoded TOML values.
+type tomlMerger struct {
+	arrays          string
+	keyField        string
+	errorOnConflict bool
+}
+
+// newTomlMerger creates a tomlMerger from the options argument of the merge_with function.
+func newTomlMerger(options any) (tomlMerger, error) {
+	merger := tomlMerger{arrays: "replace"}
+
+	table, ok := options.(map[string]any)
+	if !ok {
+		return merger, fmt.Errorf("options must be an object")
+	}
+
+	for name, value := range table {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch name {
+		case "arrays":
+			merger.arrays, ok = value.(string)
+			if ok && merger.arrays != "replace" && merger.arrays != "append" && merger.arrays != "merge" {
+				return merger, fmt.Errorf("arrays must be one of \"replace\", \"append\" or \"merge\", got: %q", merger.arrays)
+			}
+		case "key_field":
+			merger.keyField, ok = value.(string)
+		case "error_on_conflict":
+			merger.errorOnConflict, ok = value.(bool)
+		default:
+			return merger, fmt.Errorf("unsupported option %q", name)
+		}
+		if !ok {
+			return merger, fmt.Errorf("option %q has an invalid type %T", name, value)
+		}
+	}
+
+	if merger.arrays == "merge" && merger.keyField == "" {
+		return merger, fmt.Errorf("key_field must be set when arrays is \"merge\"")
+	}
+	return merger, nil
+}
+
+// merge merges override into base, returning the merged value.
+func (m tomlMerger) merge(path tomlPath, base, override any) (any, error) {
+	if base == nil {
+		return override, nil
+	}
+
+	baseKind, overrideKind := mergeKind(base), mergeKind(override)
+	if baseKind != overrideKind {
+		if m.errorOnConflict {
+			return nil, fmt.Errorf("conflicting types at %s: %s and %s", formatDocumentPath(path), baseKind, overrideKind)
+		}
+		return override, nil
+	}
+
+	switch baseValue := base.(type) {
+	case map[string]any:
+		overrideValue := override.(map[string]any)
+		result := make(map[string]any, len(baseValue)+len(overrideValue))
+		for key, value := range baseValue {
+			result[key] = value
+		}
+
+		keys := make([]string, 0, len(overrideValue))
+		for key := range overrideValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			merged, err := m.merge(path.join(keyElement(key)), result[key], overrideValue[key])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = merged
+		}
+		return result, nil
+	case []any:
+		overrideValue := override.([]any)
+		switch m.arrays {
+		case "append":
+			return append(append([]any{}, baseValue...), overrideValue...), nil
+		case "merge":
+			return m.mergeArrayOfTables(path, baseValue, overrideValue)
+		}
+		return override, nil
+	}
+	return override, nil
+}
+
+// mergeArrayOfTables merges the entries of two arrays of tables by the value
+// of the key field. Arrays which do not contain tables are replaced.
+func (m tomlMerger) mergeArrayOfTables(path tomlPath, base, override []any) (any, error) {
+	if !isArrayOfTables(base) || !isArrayOfTables(override) {
+		return override, nil
+	}
+
+	result := append([]any{}, base...)
+	for _, entry := range override {
+		keyValue, ok := entry.(map[string]any)[m.keyField]
+		index := -1
+		for i, baseEntry := range result {
+			if baseKeyValue, baseOk := baseEntry.(map[string]any)[m.keyField]; ok && baseOk && reflect.DeepEqual(keyValue, baseKeyValue) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			result = append(result, entry)
+			continue
+		}
+
+		merged, err := m.merge(path.join(indexElement(index)), result[index], entry)
+		if err != nil {
+			return nil, err
+		}
+		result[index] = merged
+	}
+	return result, nil
+}
+
+func isArrayOfTables(value []any) bool {
+	for _, entry := range value {
+		if _, ok := entry.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeKind describes the type of a value, for detecting conflicts.
+func mergeKind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "table"
+	case []any:
+		return "array"
+	case int64, float64, *big.Float:
+		return "number"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatDocumentPath(path tomlPath) string {
+	if len(path) == 0 {
+		return "the root of the document"
+	}
+	return path.String()
+}
+
+// withoutNulls removes null values from a decoded value.
+func withoutNulls(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, element := range value {
+			if element != nil {
+				result[key] = withoutNulls(element)
+			}
+		}
+		return result
+	case []any:
+		result := make([]any, 0, len(value))
+		for _, element := range value {
+			if element != nil {
+				result = append(result, withoutNulls(element))
+			}
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testMergeDocuments = `
+locals {
+	base = <<EOF
+[server]
+host = "localhost"
+port = 8080
+tags = ["base"]
+
+[[server.routes]]
+name = "health"
+path = "/health"
+
+[[server.routes]]
+name = "api"
+path = "/api"
+EOF
+
+	override = {
+		server = {
+			port   = 9090
+			tags   = ["production"]
+			routes = [{ name = "api", path = "/v2/api" }, { name = "admin", path = "/admin" }]
+		}
+	}
+}
+`
+
+	testMergeConfig = testMergeDocuments + `
+output "replace" {
+	value = provider::toml::merge(local.base, local.override)
+}
+
+output "append" {
+	value = provider::toml::merge_with({ arrays = "append" }, local.base, local.override).server.tags
+}
+
+output "null_option" {
+	value = provider::toml::merge_with({ arrays = "append", key_field = null }, local.base, local.override).server.tags
+}
+
+output "single" {
+	value = provider::toml::merge(local.base).server.port
+}
+
+output "merge" {
+	value = provider::toml::merge_with({ arrays = "merge", key_field = "name" }, local.base, local.override).server.routes
+}
+`
+
+	testMergeUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "merge" {
+	value = provider::toml::merge("a = 1", { b = { c = terraform_data.computed.output } })
+}
+
+output "merge_with" {
+	value = provider::toml::merge_with({ arrays = "append" }, "a = [1]", { a = [terraform_data.computed.output] })
+}
+`
+
+	testMergeConflictConfig = testMergeDocuments + `
+output "test" {
+	value = provider::toml::merge_with({ error_on_conflict = true }, local.base, { server = { port = "9090" } })
+}
+`
+)
+
+func TestMergeFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testMergeConflictConfig,
+				ExpectError: regexp.MustCompile(`conflicting\s+types\s+at\s+server.port:\s+number\s+and\s+string`),
+			},
+			{
+				Config: testMergeConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"replace",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"server": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"host": knownvalue.StringExact("localhost"),
+								"port": knownvalue.Int64Exact(9090),
+								"tags": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("production"),
+								}),
+								"routes": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.ObjectExact(map[string]knownvalue.Check{
+										"name": knownvalue.StringExact("api"),
+										"path": knownvalue.StringExact("/v2/api"),
+									}),
+									knownvalue.ObjectExact(map[string]knownvalue.Check{
+										"name": knownvalue.StringExact("admin"),
+										"path": knownvalue.StringExact("/admin"),
+									}),
+								}),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue("single", knownvalue.Int64Exact(8080)),
+					statecheck.ExpectKnownOutputValue(
+						"null_option",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("base"),
+							knownvalue.StringExact("production"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"append",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.StringExact("base"),
+							knownvalue.StringExact("production"),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"merge",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("health"),
+								"path": knownvalue.StringExact("/health"),
+							}),
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("api"),
+								"path": knownvalue.StringExact("/v2/api"),
+							}),
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"name": knownvalue.StringExact("admin"),
+								"path": knownvalue.StringExact("/admin"),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestMergeFunction_unknown(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testMergeUnknownConfig,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("merge"),
+						plancheck.ExpectUnknownOutputValue("merge_with"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"merge",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"a": knownvalue.Int64Exact(1),
+							"b": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"c": knownvalue.StringExact("value"),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"merge_with",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"a": knownvalue.TupleExact([]knownvalue.Check{
+								knownvalue.Int64Exact(1),
+								knownvalue.StringExact("value"),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
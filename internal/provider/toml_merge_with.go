@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = MergeWithFunction{}
+)
+
+func NewMergeWithFunction() function.Function {
+	return MergeWithFunction{}
+}
+
+type MergeWithFunction struct{}
+
+func (r MergeWithFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge_with"
+}
+
+func (r MergeWithFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Deep-merge TOML documents with options",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Deep-merges any number of TOML documents in the same way as the `merge` function, configured by",
+				"an object given as the first argument. It supports the following attributes:",
+				"",
+				"| Attribute           | Description                                                                       |",
+				"|---------------------|-----------------------------------------------------------------------------------|",
+				"| `arrays`            | How arrays are merged: `replace` (default), `append`, or `merge`.                 |",
+				"| `key_field`         | The key identifying entries of arrays of tables, required when `arrays = merge`.  |",
+				"| `error_on_conflict` | Whether to raise an error when the types of two values conflict. Default `false`. |",
+				"",
+				"With `arrays = \"merge\"`, entries of arrays of tables which have the same value for the `key_field`",
+				"key are deep-merged, and other entries are appended. Arrays which do not contain tables are",
+				"replaced.",
+				"",
+				"Values conflict when one is a table and the other isn't, when one is an array and the other isn't,",
+				"or when they are values of different types, e.g. a string and a number.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "options",
+				MarkdownDescription: "Object configuring the merge",
+				AllowUnknownValues:  true,
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "documents",
+			MarkdownDescription: "TOML content as strings, or values returned by the `decode` function",
+			AllowUnknownValues:  true,
+		},
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (r MergeWithFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var optionsArg types.Dynamic
+	var documents []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &optionsArg, &documents)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !isFullyKnown(ctx, optionsArg) || !documentsFullyKnown(ctx, documents) {
+		resp.Error = resp.Result.Set(ctx, types.DynamicUnknown())
+		return
+	}
+
+	optionsValue, err := convertFromTerraformType(optionsArg.UnderlyingValue())
+	var merger tomlMerger
+	if err == nil {
+		merger, err = newTomlMerger(optionsValue)
+	}
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The merge options are invalid.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	var result attr.Value
+	result, resp.Error = mergeDocuments(ctx, merger, documents, 1)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.DynamicValue(result))
+}
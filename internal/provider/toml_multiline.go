@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = MultilineFunction{}
+)
+
+func NewMultilineFunction() function.Function {
+	return MultilineFunction{}
+}
+
+type MultilineFunction struct{}
+
+func (r MultilineFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "multiline"
+}
+
+func (r MultilineFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Mark a string to be encoded as a TOML multiline string",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Returns an object of the form `{ __toml_type = \"string\", value = \"...\", format = \"multiline\" }`,",
+				"which the `encode` and `set` functions write as a TOML multiline string, starting on the line after",
+				"the opening delimiter.",
+				"",
+				"A multiline literal string (`'''`) is used where possible, unless the `encode` option",
+				"`quote_style = \"basic\"` is set, in which case a multiline basic string (`\"\"\"`) is used.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "value",
+				MarkdownDescription: "String to encode as a multiline string",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				tomlTypeAttribute:   types.StringType,
+				tomlValueAttribute:  types.StringType,
+				tomlFormatAttribute: types.StringType,
+			},
+		},
+	}
+}
+
+func (r MultilineFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value types.String
+
+	resp.Error = req.Arguments.Get(ctx, &value)
+
+	if resp.Error != nil {
+		return
+	}
+
+	result, err := newTaggedValueObject(ctx, tomlTypeString, value, "multiline")
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("The multiline string cannot be created.\n\nOriginal Error: %s", err))
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
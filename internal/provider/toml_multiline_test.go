@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const testMultilineConfig = `
+output "encoded" {
+	value = provider::toml::encode({
+		literal = provider::toml::multiline("first line\nsecond line\n")
+		basic   = provider::toml::multiline("it's '''quoted'''\n")
+	})
+}
+
+output "round_trip" {
+	value = provider::toml::decode(provider::toml::encode({
+		basic = provider::toml::multiline("it's '''quoted'''\n")
+	})).basic
+}
+`
+
+const testMultilineExpectedOutput = `basic = """
+it's '''quoted'''
+"""
+literal = '''
+first line
+second line
+'''
+`
+
+func TestMultilineFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testMultilineConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"encoded",
+						knownvalue.StringExact(testMultilineExpectedOutput),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"round_trip",
+						knownvalue.StringExact("it's '''quoted'''\n"),
+					),
+				},
+			},
+		},
+	})
+}
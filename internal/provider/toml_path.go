@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlPath is a path to a value within a TOML document.
+type tomlPath []tomlPathElement
+
+// tomlPathElement is a single step of a tomlPath: either a key within a table,
+// or an index within an array.
+type tomlPathElement struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func keyElement(key string) tomlPathElement {
+	return tomlPathElement{key: key}
+}
+
+func indexElement(index int) tomlPathElement {
+	return tomlPathElement{index: index, isIndex: true}
+}
+
+// parseTomlPath parses a path written using TOML dotted key syntax, with
+// optional array indexes in brackets, e.g. `tool.poetry.version`,
+// `servers."alpha.example.com".ip` or `bin[0].name`.
+func parseTomlPath(s string) (tomlPath, error) {
+	var path tomlPath
+	pos := skipPathWhitespace(s, 0)
+	if pos == len(s) {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+
+	for {
+		// Only the first element of the path may be an index without a key.
+		if len(path) > 0 || s[pos] != '[' {
+			key, next, err := parsePathKey(s, pos)
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, keyElement(key))
+			pos = skipPathWhitespace(s, next)
+		}
+
+		for pos < len(s) && s[pos] == '[' {
+			index, next, err := parsePathIndex(s, pos)
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, indexElement(index))
+			pos = skipPathWhitespace(s, next)
+		}
+
+		if pos == len(s) {
+			return path, nil
+		}
+		if s[pos] != '.' {
+			return nil, fmt.Errorf("unexpected character %q at position %d of path %q", s[pos], pos+1, s)
+		}
+		pos = skipPathWhitespace(s, pos+1)
+	}
+}
+
+// parsePathIndex parses an array index in brackets starting at pos, returning
+// the index and the position just after it.
+func parsePathIndex(s string, pos int) (int, int, error) {
+	start := skipPathWhitespace(s, pos+1)
+	end := start
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == start {
+		return 0, pos, fmt.Errorf("expected index at position %d of path %q", start+1, s)
+	}
+	index, err := strconv.Atoi(s[start:end])
+	if err != nil {
+		return 0, pos, fmt.Errorf("invalid index at position %d of path %q: %w", start+1, s, err)
+	}
+	end = skipPathWhitespace(s, end)
+	if end == len(s) || s[end] != ']' {
+		return 0, pos, fmt.Errorf("unterminated index at position %d of path %q", pos+1, s)
+	}
+	return index, end + 1, nil
+}
+
+// parsePathKey parses a single bare or quoted key starting at pos, returning the
+// key and the position just after it.
+func parsePathKey(s string, pos int) (string, int, error) {
+	if pos == len(s) {
+		return "", pos, fmt.Errorf("expected key at end of path %q", s)
+	}
+
+	switch s[pos] {
+	case '\'':
+		end := strings.IndexByte(s[pos+1:], '\'')
+		if end < 0 {
+			return "", pos, fmt.Errorf("unterminated quoted key at position %d of path %q", pos+1, s)
+		}
+		return s[pos+1 : pos+1+end], pos + end + 2, nil
+	case '"':
+		end := pos + 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) {
+			return "", pos, fmt.Errorf("unterminated quoted key at position %d of path %q", pos+1, s)
+		}
+		// Let the TOML decoder deal with escape sequences.
+		var decoded map[string]string
+		if err := toml.Unmarshal([]byte("key = "+s[pos:end+1]), &decoded); err != nil {
+			return "", pos, fmt.Errorf("invalid quoted key at position %d of path %q: %w", pos+1, s, err)
+		}
+		return decoded["key"], end + 1, nil
+	default:
+		end := pos
+		for end < len(s) && isBareKeyChar(s[end]) {
+			end++
+		}
+		if end == pos {
+			return "", pos, fmt.Errorf("unexpected character %q at position %d of path %q", s[pos], pos+1, s)
+		}
+		return s[pos:end], end, nil
+	}
+}
+
+func skipPathWhitespace(s string, pos int) int {
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// formatTomlKey formats a key so that it can be used in a TOML document,
+// quoting it if necessary.
+func formatTomlKey(key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool { return r > 0x7f || !isBareKeyChar(byte(r)) }) < 0 {
+		return key
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range key {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, "\\u%04X", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// String formats the path using TOML dotted key syntax, with indexes in brackets.
+func (p tomlPath) String() string {
+	var b strings.Builder
+	for i, element := range p {
+		if element.isIndex {
+			fmt.Fprintf(&b, "[%d]", element.index)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(formatTomlKey(element.key))
+	}
+	return b.String()
+}
+
+// keys returns the path without any indexes, as it would be written in a table header.
+func (p tomlPath) keys() tomlPath {
+	keys := make(tomlPath, 0, len(p))
+	for _, element := range p {
+		if !element.isIndex {
+			keys = append(keys, element)
+		}
+	}
+	return keys
+}
+
+func (p tomlPath) hasPrefix(prefix tomlPath) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if p[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p tomlPath) equal(other tomlPath) bool {
+	return len(p) == len(other) && p.hasPrefix(other)
+}
+
+// join returns a new path with the given elements appended.
+func (p tomlPath) join(elements ...tomlPathElement) tomlPath {
+	result := make(tomlPath, 0, len(p)+len(elements))
+	result = append(result, p...)
+	return append(result, elements...)
+}
+
+// lookupDecodedValue finds the value at the given path within a decoded TOML
+// document.
+func lookupDecodedValue(decoded any, path tomlPath) (any, bool) {
+	current := decoded
+	for _, element := range path {
+		switch value := current.(type) {
+		case map[string]any:
+			if element.isIndex {
+				return nil, false
+			}
+			next, ok := value[element.key]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			if !element.isIndex || element.index < 0 || element.index >= len(value) {
+				return nil, false
+			}
+			current = value[element.index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// tomlPathValidator validates that a string is a valid path within a TOML document.
+type tomlPathValidator struct{}
+
+var _ validator.String = tomlPathValidator{}
+
+func (v tomlPathValidator) Description(_ context.Context) string {
+	return "value must be a path using TOML dotted key syntax, e.g. \"tool.poetry.version\" or \"bin[0].name\""
+}
+
+func (v tomlPathValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v tomlPathValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseTomlPath(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid TOML path",
+			fmt.Sprintf("Attribute %s %s.\n\nOriginal Error: %s", req.Path, v.Description(ctx), err),
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseTomlPath(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		expected tomlPath
+		err      bool
+	}{
+		"bare keys": {
+			path:     "tool.poetry.version",
+			expected: tomlPath{keyElement("tool"), keyElement("poetry"), keyElement("version")},
+		},
+		"quoted keys": {
+			path:     `servers."alpha.example.com" . 'ip'`,
+			expected: tomlPath{keyElement("servers"), keyElement("alpha.example.com"), keyElement("ip")},
+		},
+		"indexes": {
+			path:     "bin[0].name",
+			expected: tomlPath{keyElement("bin"), indexElement(0), keyElement("name")},
+		},
+		"nested indexes": {
+			path:     "matrix[1] [ 2 ]",
+			expected: tomlPath{keyElement("matrix"), indexElement(1), indexElement(2)},
+		},
+		"leading index": {
+			path:     "[3].name",
+			expected: tomlPath{indexElement(3), keyElement("name")},
+		},
+		"empty": {
+			path: "",
+			err:  true,
+		},
+		"trailing dot": {
+			path: "a.",
+			err:  true,
+		},
+		"index after dot": {
+			path: "a.[0]",
+			err:  true,
+		},
+		"negative index": {
+			path: "a[-1]",
+			err:  true,
+		},
+		"unterminated index": {
+			path: "a[1",
+			err:  true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseTomlPath(testCase.path)
+			if testCase.err {
+				if err == nil {
+					t.Fatalf("expected error, got path %s", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !path.equal(testCase.expected) {
+				t.Errorf("unexpected path %s, expected %s", path, testCase.expected)
+			}
+		})
+	}
+}
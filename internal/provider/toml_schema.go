@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// tomlSchemaViolation is a violation of a JSON Schema by a TOML document.
+type tomlSchemaViolation struct {
+	path tomlPath
+	// line is the line of the document on which the value at path is defined,
+	// or 0 if it is unknown.
+	line    int
+	message string
+}
+
+// String formats the violation for use in diagnostics.
+func (v tomlSchemaViolation) String() string {
+	location := "the document root"
+	if len(v.path) > 0 {
+		location = v.path.String()
+	}
+	if v.line > 0 {
+		location = fmt.Sprintf("%s (line %d)", location, v.line)
+	}
+	return location + ": " + v.message
+}
+
+// loadTomlSchema compiles a JSON Schema, given either as JSON content or as
+// the path to a local file. Relative paths, including those of any schemas
+// referenced using `$ref`, are resolved against baseDir. Schemas are never
+// loaded over the network.
+func loadTomlSchema(schema string, baseDir string) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = loadLocalSchema
+
+	if baseDir == "" {
+		baseDir = "."
+	}
+	absBaseDir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	schema = strings.TrimSpace(schema)
+	if json.Valid([]byte(schema)) {
+		schemaURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(absBaseDir, "schema.json"))}).String()
+		if err := compiler.AddResource(schemaURL, strings.NewReader(schema)); err != nil {
+			return nil, err
+		}
+		return compiler.Compile(schemaURL)
+	}
+
+	if strings.Contains(schema, "://") {
+		return nil, fmt.Errorf("the schema %q cannot be loaded, only local files are supported", schema)
+	}
+	if !filepath.IsAbs(schema) {
+		schema = filepath.Join(absBaseDir, schema)
+	}
+	return compiler.Compile(schema)
+}
+
+// loadLocalSchema loads schemas from local files only.
+func loadLocalSchema(s string) (io.ReadCloser, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "file" {
+		return nil, fmt.Errorf("the schema %q cannot be loaded, only local files are supported", s)
+	}
+	return jsonschema.Loaders["file"](s)
+}
+
+// tomlSchemaDirective returns the schema referenced by a `#:schema` directive
+// in the leading comments of a TOML document, if any.
+func tomlSchemaDirective(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if directive, ok := strings.CutPrefix(line, "#:schema"); ok {
+			return strings.TrimSpace(directive)
+		}
+	}
+	return ""
+}
+
+// validateTomlSchema validates a decoded TOML document against a JSON Schema,
+// returning every violation. The document is validated in the form returned by
+// the decode function. If the document source is given, violations include the
+// line on which the offending value is defined.
+func validateTomlSchema(schema *jsonschema.Schema, decoded any, doc *tomlDocument) ([]tomlSchemaViolation, error) {
+	_, terraformValue, diags := convertToTerraformType(decoded)
+	if diags.HasError() {
+		return nil, fmt.Errorf("the document cannot be converted: %s", diags[0].Detail())
+	}
+	value, err := convertFromTerraformType(terraformValue)
+	if err != nil {
+		return nil, fmt.Errorf("the document cannot be converted: %s", err)
+	}
+
+	value = jsonSchemaValue(value)
+
+	err = schema.Validate(value)
+	var validationError *jsonschema.ValidationError
+	if errors.As(err, &validationError) {
+		var violations []tomlSchemaViolation
+		collectTomlSchemaViolations(validationError, value, doc, &violations)
+		sort.SliceStable(violations, func(i, j int) bool {
+			return violations[i].line < violations[j].line
+		})
+		return violations, nil
+	}
+	return nil, err
+}
+
+// jsonSchemaValue replaces numbers which cannot be represented exactly by a
+// TOML integer or float, which the JSON Schema validator does not support, by
+// their JSON representation.
+func jsonSchemaValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, element := range value {
+			result[key] = jsonSchemaValue(element)
+		}
+		return result
+	case []any:
+		result := make([]any, len(value))
+		for i, element := range value {
+			result[i] = jsonSchemaValue(element)
+		}
+		return result
+	case *big.Float:
+		return json.Number(value.Text('g', -1))
+	}
+	return value
+}
+
+// checkTomlSchema validates TOML content against the JSON Schema given in the
+// `schema` attribute of a resource or data source, adding an error to the
+// given attribute for each violation.
+func checkTomlSchema(schemaArg string, baseDir string, content []byte, attributePath path.Path, diags *diag.Diagnostics) {
+	schema, err := loadTomlSchema(schemaArg, baseDir)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("schema"),
+			"Invalid JSON Schema",
+			fmt.Sprintf("The JSON Schema cannot be loaded.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	doc, err := parseTomlDocument(content)
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"TOML schema validation error",
+			fmt.Sprintf("The TOML content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError(content, "", err)),
+		)
+		return
+	}
+
+	violations, err := validateTomlSchema(schema, doc.decoded, doc)
+	if err != nil {
+		diags.AddAttributeError(
+			attributePath,
+			"TOML schema validation error",
+			fmt.Sprintf("The TOML content cannot be validated.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+	for _, violation := range violations {
+		diags.AddAttributeError(
+			attributePath,
+			"TOML schema validation error",
+			fmt.Sprintf("The TOML content does not match the JSON Schema at %s", violation),
+		)
+	}
+}
+
+// collectTomlSchemaViolations collects the most specific errors of a validation error.
+func collectTomlSchemaViolations(validationError *jsonschema.ValidationError, value any, doc *tomlDocument, violations *[]tomlSchemaViolation) {
+	if len(validationError.Causes) > 0 {
+		for _, cause := range validationError.Causes {
+			collectTomlSchemaViolations(cause, value, doc, violations)
+		}
+		return
+	}
+
+	violation := tomlSchemaViolation{
+		path:    jsonPointerToTomlPath(validationError.InstanceLocation, value),
+		message: validationError.Message,
+	}
+	if doc != nil {
+		violation.line = doc.line(violation.path)
+	}
+	*violations = append(*violations, violation)
+}
+
+// jsonPointerToTomlPath converts a JSON pointer within a decoded value to a tomlPath.
+func jsonPointerToTomlPath(pointer string, value any) tomlPath {
+	var path tomlPath
+	if pointer == "" {
+		return path
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := value.(type) {
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return path
+			}
+			path = append(path, indexElement(index))
+			value = current[index]
+		case map[string]any:
+			path = append(path, keyElement(token))
+			value = current[token]
+		default:
+			return path
+		}
+	}
+	return path
+}
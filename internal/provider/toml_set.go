@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = SetFunction{}
+)
+
+func NewSetFunction() function.Function {
+	return SetFunction{}
+}
+
+type SetFunction struct{}
+
+func (r SetFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "set"
+}
+
+func (r SetFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Set the value at a path within TOML content",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Sets the value at the given path within TOML content, returning the modified content.",
+				"",
+				"Only the value at the path is rewritten, so the comments, key order and formatting of the rest",
+				"of the content are preserved. Missing tables are created, and an entry can be appended to an",
+				"array (or array of tables) by using the length of the array as the index, e.g. `bin[2]` for an",
+				"array with two entries.",
+				"",
+				"The path uses the same syntax as the `get` function, and the value is encoded in the same way as",
+				"the `encode` function, with tables nested within other tables written inline. A string which",
+				"replaces another string is written in the same style, basic, literal or multiline, where possible.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "input",
+				MarkdownDescription: "TOML content to modify",
+				Validators: []function.StringParameterValidator{
+					tomlContentValidator{},
+				},
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "Path of the value within the content",
+			},
+			function.DynamicParameter{
+				Name:                "value",
+				MarkdownDescription: "Terraform value to set",
+				AllowUnknownValues:  true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (r SetFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var data, pathString string
+	var value types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &data, &pathString, &value)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !isFullyKnown(ctx, value) {
+		resp.Error = resp.Result.Set(ctx, types.StringUnknown())
+		return
+	}
+
+	doc, keyPath, funcErr := parseDocumentAndPath(data, pathString)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	setValue, err := convertFromTerraformType(value.UnderlyingValue())
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			2,
+			fmt.Sprintf("The value cannot be converted.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	content, err := doc.Set(keyPath, setValue)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			2,
+			fmt.Sprintf("The value cannot be set at path %q.\n\nOriginal Error: %s", pathString, err),
+		)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, types.StringValue(string(content)))
+}
+
+// parseDocumentAndPath parses the content and path arguments shared by the
+// functions which edit TOML content.
+func parseDocumentAndPath(data string, pathString string) (*tomlDocument, tomlPath, *function.FuncError) {
+	doc, err := parseTomlDocument([]byte(data))
+	if err != nil {
+		return nil, nil, function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The TOML content cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(data), "", err)),
+		)
+	}
+
+	keyPath, err := parseTomlPath(pathString)
+	if err != nil {
+		return nil, nil, function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The path is invalid.\n\nOriginal Error: %s", err),
+		)
+	}
+	return doc, keyPath, nil
+}
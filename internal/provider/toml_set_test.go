@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testEditFunctionDocument = `
+locals {
+	document = <<EOF
+# Package metadata.
+[package]
+name = "example" # The crate name.
+version = "0.1.0"
+
+[[bin]]
+name = "first"
+EOF
+}
+`
+
+	testSetConfig = testEditFunctionDocument + `
+output "test" {
+	value = provider::toml::set(
+		provider::toml::set(
+			provider::toml::set(local.document, "package.version", "0.2.0"),
+			"bin[1]",
+			{ name = "second" },
+		),
+		"tool.black.line-length",
+		88,
+	)
+}
+`
+
+	testSetInvalidInputConfig = `
+output "test" {
+	value = provider::toml::set("[package]\nname = example\n", "package.version", "0.2.0")
+}
+`
+
+	testSetExpectedOutput = `# Package metadata.
+[package]
+name = "example" # The crate name.
+version = "0.2.0"
+
+[[bin]]
+name = "first"
+
+[[bin]]
+name = 'second'
+
+[tool.black]
+line-length = 88
+`
+)
+
+func TestSetFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testSetInvalidInputConfig,
+				ExpectError: regexp.MustCompile(`The\s+TOML\s+content\s+cannot\s+be\s+decoded(.|\s)+At\s+line\s+2,\s+column\s+8\s+\(key\s+package\.name\)`),
+			},
+			{
+				Config: testSetConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.StringExact(testSetExpectedOutput),
+					),
+				},
+			},
+		},
+	})
+}
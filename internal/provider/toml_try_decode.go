@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = TryDecodeFunction{}
+)
+
+// tomlDecodeErrorAttrTypes are the attribute types of the errors returned by
+// the try_decode function.
+var tomlDecodeErrorAttrTypes = map[string]attr.Type{
+	"message": types.StringType,
+	"line":    types.Int64Type,
+	"column":  types.Int64Type,
+	"key":     types.StringType,
+}
+
+// tryDecodeResultAttrTypes are the attribute types of the object returned by
+// the try_decode function.
+var tryDecodeResultAttrTypes = map[string]attr.Type{
+	"valid":  types.BoolType,
+	"value":  types.DynamicType,
+	"errors": types.ListType{ElemType: types.ObjectType{AttrTypes: tomlDecodeErrorAttrTypes}},
+}
+
+func NewTryDecodeFunction() function.Function {
+	return TryDecodeFunction{}
+}
+
+type TryDecodeFunction struct{}
+
+func (r TryDecodeFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "try_decode"
+}
+
+func (r TryDecodeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Decode TOML content, returning any errors instead of failing",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Interprets a given string as TOML in the same way as the `decode` function, but returns an object",
+				"describing the result rather than failing if the content is invalid. This makes it possible to",
+				"report why content is invalid within `precondition`, `validation` and `check` blocks, which",
+				"`can(provider::toml::decode(...))` does not.",
+				"",
+				"The result has the following attributes:",
+				"",
+				"| Attribute | Description                                                                      |",
+				"|-----------|----------------------------------------------------------------------------------|",
+				"| `valid`   | Whether the content is valid TOML.                                               |",
+				"| `value`   | The decoded content, as returned by the `decode` function, or `null` if invalid. |",
+				"| `errors`  | A list of the errors in the content, which is empty if it is valid.              |",
+				"",
+				"Each error is an object with the following attributes:",
+				"",
+				"| Attribute | Description                                                               |",
+				"|-----------|---------------------------------------------------------------------------|",
+				"| `message` | A description of the error.                                               |",
+				"| `line`    | The line on which the error occurred, or `null` if unknown.               |",
+				"| `column`  | The column at which the error occurred, counted in characters, or `null`. |",
+				"| `key`     | The key being defined when the error occurred, or `null` if unknown.      |",
+				"",
+				"The optional second argument configures the decoding, and supports the same attributes as for",
+				"the `decode` function. Invalid options are still reported as errors.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "input",
+				MarkdownDescription: "TOML file content to decode",
+			},
+		},
+		VariadicParameter: function.DynamicParameter{
+			Name:                "options",
+			MarkdownDescription: "Optional object configuring the decoding",
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: tryDecodeResultAttrTypes,
+		},
+	}
+}
+
+func (r TryDecodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var data string
+	var optionsArgs []types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &data, &optionsArgs)
+
+	if resp.Error != nil {
+		return
+	}
+
+	var options tomlDecoderOptions
+	options, resp.Error = parseDecodeOptionsArgument(optionsArgs)
+	if resp.Error != nil {
+		return
+	}
+
+	value := types.DynamicNull()
+	var decodeErrors []attr.Value
+	terraformValue, diags, err := decodeTomlContent([]byte(data), options)
+	switch {
+	case err != nil:
+		message := strings.TrimPrefix(err.Error(), "toml: ")
+		line, column, key := types.Int64Null(), types.Int64Null(), types.StringNull()
+		if offset, keyPath, ok := locateTomlDecodeError([]byte(data), err); ok {
+			lineNumber, columnNumber := tomlPosition([]byte(data), offset)
+			line, column = types.Int64Value(int64(lineNumber)), types.Int64Value(int64(columnNumber))
+			if len(keyPath) > 0 {
+				key = types.StringValue(keyPath.String())
+			}
+		}
+		decodeErrors = append(decodeErrors, newTomlDecodeErrorObject(message, line, column, key))
+	case diags.HasError():
+		for _, d := range diags.Errors() {
+			decodeErrors = append(decodeErrors, newTomlDecodeErrorObject(d.Detail(), types.Int64Null(), types.Int64Null(), types.StringNull()))
+		}
+	default:
+		value = types.DynamicValue(terraformValue)
+	}
+
+	errorsValue, diags := types.ListValue(types.ObjectType{AttrTypes: tomlDecodeErrorAttrTypes}, decodeErrors)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	result, diags := types.ObjectValue(tryDecodeResultAttrTypes, map[string]attr.Value{
+		"valid":  types.BoolValue(len(decodeErrors) == 0),
+		"value":  value,
+		"errors": errorsValue,
+	})
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
+
+func newTomlDecodeErrorObject(message string, line types.Int64, column types.Int64, key types.String) attr.Value {
+	return types.ObjectValueMust(tomlDecodeErrorAttrTypes, map[string]attr.Value{
+		"message": types.StringValue(message),
+		"line":    line,
+		"column":  column,
+		"key":     key,
+	})
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const testTryDecodeConfig = `
+output "valid" {
+	value = provider::toml::try_decode("a = 1\nb = [2001-01-01]\n", { datetimes = "tagged" })
+}
+
+output "invalid" {
+	value = provider::toml::try_decode("a = 1\n\n[section]\nb = \"\\q\"\n")
+}
+
+output "duplicate" {
+	value = provider::toml::try_decode("a = 1\na = 2\n")
+}
+`
+
+const testTryDecodeInvalidOptionsConfig = `
+output "test" {
+	value = provider::toml::try_decode("a = 1", { datetimes = "native" })
+}
+`
+
+func TestTryDecodeFunction(t *testing.T) {
+	decodeError := func(message string, line int64, column int64, key string) knownvalue.Check {
+		return knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"message": knownvalue.StringExact(message),
+			"line":    knownvalue.Int64Exact(line),
+			"column":  knownvalue.Int64Exact(column),
+			"key":     knownvalue.StringExact(key),
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testTryDecodeInvalidOptionsConfig,
+				ExpectError: regexp.MustCompile(`datetimes\s+must\s+be\s+one\s+of`),
+			},
+			{
+				Config: testTryDecodeConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"valid",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"valid": knownvalue.Bool(true),
+							"value": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"a": knownvalue.Int64Exact(1),
+								"b": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.ObjectExact(map[string]knownvalue.Check{
+										"__toml_type": knownvalue.StringExact("local_date"),
+										"value":       knownvalue.StringExact("2001-01-01"),
+									}),
+								}),
+							}),
+							"errors": knownvalue.ListExact([]knownvalue.Check{}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"invalid",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"valid": knownvalue.Bool(false),
+							"value": knownvalue.Null(),
+							"errors": knownvalue.ListExact([]knownvalue.Check{
+								decodeError("invalid escaped character U+0071 'q'", 4, 7, "section.b"),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue(
+						"duplicate",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"valid": knownvalue.Bool(false),
+							"value": knownvalue.Null(),
+							"errors": knownvalue.ListExact([]knownvalue.Check{
+								decodeError("key a is already defined", 2, 1, "a"),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
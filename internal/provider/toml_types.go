@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Tagged values represent a TOML value of an explicit type, such as a date or
+// a float, as objects of the form `{ __toml_type = "...", value = ... }`, with
+// an optional `format` attribute.
+const (
+	tomlTypeAttribute   = "__toml_type"
+	tomlValueAttribute  = "value"
+	tomlFormatAttribute = "format"
+
+	tomlTypeOffsetDateTime = "offset_datetime"
+	tomlTypeLocalDateTime  = "local_datetime"
+	tomlTypeLocalDate      = "local_date"
+	tomlTypeLocalTime      = "local_time"
+	tomlTypeFloat          = "float"
+	tomlTypeInteger        = "integer"
+	tomlTypeString         = "string"
+)
+
+// tomlIntegerFormats are the supported formats of integer tagged values,
+// along with their prefix and base.
+var tomlIntegerFormats = map[string]struct {
+	prefix string
+	base   int
+}{
+	"decimal": {"", 10},
+	"hex":     {"0x", 16},
+	"octal":   {"0o", 8},
+	"binary":  {"0b", 2},
+}
+
+// tomlTaggedValue is a TOML value of an explicit type.
+type tomlTaggedValue struct {
+	tomlType string
+	value    any
+	format   string
+}
+
+func newTaggedValue(tomlType string, value string) map[string]any {
+	return map[string]any{
+		tomlTypeAttribute:  tomlType,
+		tomlValueAttribute: value,
+	}
+}
+
+// asTaggedValue returns the tagged value represented by a value returned by
+// convertFromTerraformType, if any.
+func asTaggedValue(value any) (tomlTaggedValue, bool) {
+	var tagged tomlTaggedValue
+
+	table, ok := value.(map[string]any)
+	if !ok {
+		return tagged, false
+	}
+	for key := range table {
+		if key != tomlTypeAttribute && key != tomlValueAttribute && key != tomlFormatAttribute {
+			return tagged, false
+		}
+	}
+
+	tagged.tomlType, ok = table[tomlTypeAttribute].(string)
+	if !ok {
+		return tagged, false
+	}
+	tagged.value, ok = table[tomlValueAttribute]
+	if !ok {
+		return tagged, false
+	}
+	if format, ok := table[tomlFormatAttribute]; ok && format != nil {
+		if tagged.format, ok = format.(string); !ok {
+			return tagged, false
+		}
+	}
+	return tagged, true
+}
+
+// asTomlTable returns the value as a table, unless it is not a table or is a
+// tagged value.
+func asTomlTable(value any) (map[string]any, bool) {
+	table, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	if _, tagged := asTaggedValue(table); tagged {
+		return nil, false
+	}
+	return table, true
+}
+
+// tagDecodedValue replaces the date and time values of a decoded TOML document
+// with tagged values.
+func tagDecodedValue(value any) any {
+	switch value := value.(type) {
+	case time.Time:
+		return newTaggedValue(tomlTypeOffsetDateTime, value.Format(time.RFC3339Nano))
+	case toml.LocalDateTime:
+		return newTaggedValue(tomlTypeLocalDateTime, value.String())
+	case toml.LocalDate:
+		return newTaggedValue(tomlTypeLocalDate, value.String())
+	case toml.LocalTime:
+		return newTaggedValue(tomlTypeLocalTime, value.String())
+	case []any:
+		result := make([]any, len(value))
+		for i, element := range value {
+			result[i] = tagDecodedValue(element)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, element := range value {
+			result[key] = tagDecodedValue(element)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// tagSpecialFloats replaces the infinite and NaN floats of a decoded TOML
+// document with tagged values, as convertToTerraformType does.
+func tagSpecialFloats(value any) any {
+	switch value := value.(type) {
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return newTaggedValue(tomlTypeFloat, formatSpecialFloat(value))
+		}
+		return value
+	case []any:
+		result := make([]any, len(value))
+		for i, element := range value {
+			result[i] = tagSpecialFloats(element)
+		}
+		return result
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, element := range value {
+			result[key] = tagSpecialFloats(element)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// checkTaggedDatetime checks that a string is a valid TOML date or time value
+// of the given type.
+func checkTaggedDatetime(tomlType string, text string) error {
+	var expected any
+	switch tomlType {
+	case tomlTypeOffsetDateTime:
+		expected = time.Time{}
+	case tomlTypeLocalDateTime:
+		expected = toml.LocalDateTime{}
+	case tomlTypeLocalDate:
+		expected = toml.LocalDate{}
+	case tomlTypeLocalTime:
+		expected = toml.LocalTime{}
+	default:
+		return fmt.Errorf("unsupported %s %q", tomlTypeAttribute, tomlType)
+	}
+
+	// The value is written as is, so check that it is a single value of the
+	// expected type, without any comment or surrounding whitespace which
+	// would be valid after a key but not within an array or inline table.
+	if strings.ContainsAny(text, "#\r\n") || strings.TrimSpace(text) != text {
+		return fmt.Errorf("%q is not a valid %s", text, tomlType)
+	}
+	var decoded map[string]any
+	err := toml.Unmarshal([]byte("v = "+text), &decoded)
+	if err != nil || len(decoded) != 1 || reflect.TypeOf(decoded["v"]) != reflect.TypeOf(expected) {
+		return fmt.Errorf("%q is not a valid %s", text, tomlType)
+	}
+	return nil
+}
+
+// datetimeTypeOfString returns the type of TOML date or time value which a
+// string is valid as, if any.
+func datetimeTypeOfString(text string) (string, bool) {
+	// Every date and time value starts with at least two digits.
+	if len(text) < 8 || text[0] < '0' || text[0] > '9' || text[1] < '0' || text[1] > '9' {
+		return "", false
+	}
+	for _, tomlType := range []string{tomlTypeOffsetDateTime, tomlTypeLocalDateTime, tomlTypeLocalDate, tomlTypeLocalTime} {
+		if checkTaggedDatetime(tomlType, text) == nil {
+			return tomlType, true
+		}
+	}
+	return "", false
+}
+
+// formatTaggedInteger formats an integer in the given format.
+func formatTaggedInteger(value int64, format string) (string, error) {
+	if format == "" {
+		format = "decimal"
+	}
+	integerFormat, ok := tomlIntegerFormats[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported integer format %q, expected one of \"decimal\", \"hex\", \"octal\" or \"binary\"", format)
+	}
+	if value < 0 && integerFormat.base != 10 {
+		return "", fmt.Errorf("negative integers can only be written in decimal, got: %d", value)
+	}
+	return integerFormat.prefix + strconv.FormatInt(value, integerFormat.base), nil
+}
+
+// formatSpecialFloat formats an infinite or NaN float as the value of a float
+// tagged value: `inf`, `-inf` or `nan`.
+func formatSpecialFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	default:
+		return "nan"
+	}
+}
+
+// parseSpecialFloat parses the value of a float tagged value given as a string.
+func parseSpecialFloat(value string) (float64, error) {
+	switch value {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	default:
+		return 0, fmt.Errorf("%q is not a valid float, expected a number, \"inf\", \"-inf\" or \"nan\"", value)
+	}
+}
+
+// canBeMultilineLiteralString returns whether a string can be written as a
+// multiline literal string.
+func canBeMultilineLiteralString(value string) bool {
+	if strings.Contains(value, "'''") || strings.HasSuffix(value, "'") {
+		return false
+	}
+	for i, c := range []byte(value) {
+		if c == '\r' && (i+1 == len(value) || value[i+1] != '\n') {
+			return false
+		}
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// newTaggedValueObject returns a tagged value as a Terraform object, omitting
+// the format if it is empty.
+func newTaggedValueObject(ctx context.Context, tomlType string, value attr.Value, format string) (types.Object, error) {
+	attributeTypes := map[string]attr.Type{
+		tomlTypeAttribute:  types.StringType,
+		tomlValueAttribute: value.Type(ctx),
+	}
+	attributes := map[string]attr.Value{
+		tomlTypeAttribute:  types.StringValue(tomlType),
+		tomlValueAttribute: value,
+	}
+	if format != "" {
+		attributeTypes[tomlFormatAttribute] = types.StringType
+		attributes[tomlFormatAttribute] = types.StringValue(format)
+	}
+
+	object, diags := types.ObjectValue(attributeTypes, attributes)
+	if diags.HasError() {
+		return object, fmt.Errorf("%s", diags[0].Detail())
+	}
+	return object, nil
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = ValidateFunction{}
+)
+
+// tomlSchemaViolationAttrTypes are the attribute types of the objects returned
+// by the validate function.
+var tomlSchemaViolationAttrTypes = map[string]attr.Type{
+	"path":    types.StringType,
+	"line":    types.Int64Type,
+	"message": types.StringType,
+}
+
+func NewValidateFunction() function.Function {
+	return ValidateFunction{}
+}
+
+type ValidateFunction struct{}
+
+func (r ValidateFunction) Metadata(_ context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate"
+}
+
+func (r ValidateFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validate a TOML document against a JSON Schema",
+		MarkdownDescription: strings.Join(
+			[]string{
+				"Validates a TOML document against a [JSON Schema](https://json-schema.org/), returning a list of",
+				"every violation. The list is empty if the document is valid. The document is validated in the form",
+				"returned by the `decode` function, so dates and times are validated as strings.",
+				"",
+				"Each violation is an object with the following attributes:",
+				"",
+				"| Attribute | Description                                                                               |",
+				"|-----------|-------------------------------------------------------------------------------------------|",
+				"| `path`    | The path of the offending value, using the same syntax as the `get` function.             |",
+				"| `line`    | The line on which the value is defined, or `null` if the document was not given as TOML. |",
+				"| `message` | A description of the violation.                                                           |",
+				"",
+				"The schema may be given as JSON content, or as the path to a local file. If the schema is empty,",
+				"the schema referenced by a `#:schema` directive in the leading comments of the document is used.",
+				"Relative paths, including those of schemas referenced using `$ref`, are resolved against the",
+				"current working directory, so it is best to build them using `path.module`. Schemas are never",
+				"loaded over the network.",
+			},
+			"\n",
+		),
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name:                "document",
+				MarkdownDescription: "TOML content as a string, or a value returned by the `decode` function",
+				AllowUnknownValues:  true,
+			},
+			function.StringParameter{
+				Name:                "schema",
+				MarkdownDescription: "JSON Schema content, or the path to a local JSON Schema file",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{AttrTypes: tomlSchemaViolationAttrTypes},
+		},
+	}
+}
+
+func (r ValidateFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var document types.Dynamic
+	var schemaArg string
+
+	resp.Error = req.Arguments.Get(ctx, &document, &schemaArg)
+
+	if resp.Error != nil {
+		return
+	}
+
+	if !isFullyKnown(ctx, document) {
+		resp.Error = resp.Result.Set(ctx, types.ListUnknown(types.ObjectType{AttrTypes: tomlSchemaViolationAttrTypes}))
+		return
+	}
+
+	var decodedContent any
+	var doc *tomlDocument
+	if content, ok := document.UnderlyingValue().(types.String); ok {
+		var err error
+		doc, err = parseTomlDocument([]byte(content.ValueString()))
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(
+				0,
+				fmt.Sprintf("The TOML document cannot be decoded.\n\nOriginal Error: %s", describeTomlDecodeError([]byte(content.ValueString()), "", err)),
+			)
+			return
+		}
+		decodedContent = doc.decoded
+		if strings.TrimSpace(schemaArg) == "" {
+			schemaArg = tomlSchemaDirective(doc.data)
+		}
+	} else {
+		decodedContent, resp.Error = decodeDocumentArgument(document, 0)
+		if resp.Error != nil {
+			return
+		}
+	}
+
+	if strings.TrimSpace(schemaArg) == "" {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			"No schema was given, and the document does not contain a #:schema directive",
+		)
+		return
+	}
+
+	schema, err := loadTomlSchema(schemaArg, "")
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			1,
+			fmt.Sprintf("The JSON Schema cannot be loaded.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	violations, err := validateTomlSchema(schema, decodedContent, doc)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(
+			0,
+			fmt.Sprintf("The TOML document cannot be validated.\n\nOriginal Error: %s", err),
+		)
+		return
+	}
+
+	elements := make([]attr.Value, len(violations))
+	for i, violation := range violations {
+		line := types.Int64Null()
+		if violation.line > 0 {
+			line = types.Int64Value(int64(violation.line))
+		}
+		elements[i] = types.ObjectValueMust(tomlSchemaViolationAttrTypes, map[string]attr.Value{
+			"path":    types.StringValue(violation.path.String()),
+			"line":    line,
+			"message": types.StringValue(violation.message),
+		})
+	}
+
+	result, diags := types.ListValue(types.ObjectType{AttrTypes: tomlSchemaViolationAttrTypes}, elements)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result)
+}
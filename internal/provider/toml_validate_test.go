@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+const (
+	testValidateSchema = `{
+  "type": "object",
+  "properties": {
+    "package": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "version": {"type": "string"}
+      }
+    }
+  }
+}`
+
+	testValidateConfig = `
+locals {
+	schema = %q
+	document = <<EOF
+#:schema %s
+
+[package]
+version = 1
+EOF
+}
+
+output "inline" {
+	value = provider::toml::validate(local.document, local.schema)
+}
+
+output "directive" {
+	value = provider::toml::validate(local.document, "")
+}
+
+output "decoded" {
+	value = provider::toml::validate(provider::toml::decode(local.document), local.schema)
+}
+
+output "inexact" {
+	value = provider::toml::validate({ package = { name = "example", version = 1/3 }, size = 100000000000000000000000 }, local.schema)
+}
+
+output "valid" {
+	value = provider::toml::validate("[package]\nname = \"example\"\n", local.schema)
+}
+`
+
+	testValidateUnknownConfig = `
+resource "terraform_data" "computed" {
+	input = "value"
+}
+
+output "test" {
+	value = provider::toml::validate({ package = { name = terraform_data.computed.output } }, %q)
+}
+`
+
+	testValidateRemoteConfig = `
+output "test" {
+	value = provider::toml::validate("a = 1", "https://json.schemastore.org/cargo.json")
+}
+`
+)
+
+func TestValidateFunction(t *testing.T) {
+	schemaFilename := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schemaFilename, []byte(testValidateSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	violations := func(withLines bool) knownvalue.Check {
+		line := func(line int64) knownvalue.Check {
+			if withLines {
+				return knownvalue.Int64Exact(line)
+			}
+			return knownvalue.Null()
+		}
+		return knownvalue.ListExact([]knownvalue.Check{
+			knownvalue.ObjectExact(map[string]knownvalue.Check{
+				"path":    knownvalue.StringExact("package"),
+				"line":    line(3),
+				"message": knownvalue.StringExact("missing properties: 'name'"),
+			}),
+			knownvalue.ObjectExact(map[string]knownvalue.Check{
+				"path":    knownvalue.StringExact("package.version"),
+				"line":    line(4),
+				"message": knownvalue.StringExact("expected string, but got number"),
+			}),
+		})
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testValidateRemoteConfig,
+				ExpectError: regexp.MustCompile(`only\s+local\s+files\s+are\s+supported`),
+			},
+			{
+				Config: fmt.Sprintf(testValidateConfig, testValidateSchema, schemaFilename),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("inline", violations(true)),
+					statecheck.ExpectKnownOutputValue("directive", violations(true)),
+					statecheck.ExpectKnownOutputValue("decoded", violations(false)),
+					statecheck.ExpectKnownOutputValue(
+						"inexact",
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"path":    knownvalue.StringExact("package.version"),
+								"line":    knownvalue.Null(),
+								"message": knownvalue.StringExact("expected string, but got number"),
+							}),
+						}),
+					),
+					statecheck.ExpectKnownOutputValue("valid", knownvalue.ListExact([]knownvalue.Check{})),
+				},
+			},
+		},
+	})
+}
+
+func TestValidateFunction_unknown(t *testing.T) {
+	schemaFilename := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(schemaFilename, []byte(testValidateSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testValidateUnknownConfig, schemaFilename),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownOutputValue("test"),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ListExact([]knownvalue.Check{})),
+				},
+			},
+		},
+	})
+}